@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/xshoji/go-keywordminer/internal/batch"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+)
+
+var (
+	// Command options for "batch" sub command
+	batchCommandDescription     = "Analyzes many URLs concurrently. \n  Reads one URL per line and streams one JSON result per URL (NDJSON) as each finishes."
+	batchCommandOptionMaxLength = 0
+	batchFlagSet                = flag.NewFlagSet("keywordminer batch", flag.ExitOnError)
+	batchOptionInput            = defineFlagSetValue(batchFlagSet, "i", "input" /*       */, UsageRequiredPrefix+"File with one URL per line (\"-\" reads stdin)", "").(*string)
+	batchOptionConcurrency      = defineFlagSetValue(batchFlagSet, "c", "concurrency" /* */, "Number of URLs analyzed concurrently", 4).(*int)
+	batchOptionMaxKeywords      = defineFlagSetValue(batchFlagSet, "n", "max-keywords" /**/, "Number of keywords per URL", 20).(*int)
+	batchOptionDedupe           = defineFlagSetValue(batchFlagSet, "U", "dedupe" /*      */, "Skip results whose canonical URL was already output", false).(*bool)
+	batchOptionCommon           = defineCommonOptions(batchFlagSet)
+)
+
+func init() {
+	formatFlagSetUsage(batchFlagSet, batchCommandDescription, &batchCommandOptionMaxLength, new(bytes.Buffer))
+}
+
+// runBatch は "batch" サブコマンドを実行し、終了コードを返します
+func runBatch(args []string) int {
+	_ = batchFlagSet.Parse(args)
+	if *batchOptionInput == "" {
+		batchFlagSet.Usage()
+		return 0
+	}
+
+	cfg, err := batchOptionCommon.buildConfig()
+	if err != nil {
+		handleError(err, "buildConfig")
+		return 1
+	}
+
+	var input io.Reader = os.Stdin
+	if *batchOptionInput != "-" {
+		f, err := os.Open(*batchOptionInput)
+		if err != nil {
+			handleError(err, "Open URL list")
+			return 1
+		}
+		defer f.Close()
+		input = f
+	}
+	urls, err := batch.ReadURLList(input)
+	if err != nil {
+		handleError(err, "ReadURLList")
+		return 1
+	}
+
+	// Ctrl+C で未処理のURLを打ち切る
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// 1行に1つの解析結果を出力（NDJSON）
+	encoder := json.NewEncoder(os.Stdout)
+	opts := batch.Options{Concurrency: *batchOptionConcurrency, MaxKeywords: *batchOptionMaxKeywords, DedupeCanonical: *batchOptionDedupe}
+	err = batch.Run(ctx, urls, cfg, opts, func(result *types.AnalysisResult) {
+		if err := encoder.Encode(result); err != nil {
+			handleError(err, "JSON Encode")
+		}
+	})
+	if err != nil {
+		handleError(err, "batch.Run")
+		return 1
+	}
+	return 0
+}
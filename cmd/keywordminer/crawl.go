@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/xshoji/go-keywordminer/internal/crawler"
+)
+
+var (
+	// Command options for "crawl" sub command
+	crawlCommandDescription     = "Crawls a site by following links (and sitemap.xml) and analyzes every page. \n  Outputs per-page keywords and keywords aggregated over the whole site."
+	crawlCommandOptionMaxLength = 0
+	crawlFlagSet                = flag.NewFlagSet("keywordminer crawl", flag.ExitOnError)
+	crawlOptionUrl              = defineFlagSetValue(crawlFlagSet, "u", "url" /*          */, "Start URL; required unless --sitemap is given", "").(*string)
+	crawlOptionSitemap          = defineFlagSetValue(crawlFlagSet, "s", "sitemap" /*      */, "sitemap.xml or sitemap index URL used as additional start URLs", "").(*string)
+	crawlOptionDepth            = defineFlagSetValue(crawlFlagSet, "d", "depth" /*        */, "Link depth to follow from the start URLs (0 analyzes only the start URLs)", 1).(*int)
+	crawlOptionMaxPages         = defineFlagSetValue(crawlFlagSet, "m", "max-pages" /*    */, "Maximum number of pages to analyze", crawler.DefaultMaxPages).(*int)
+	crawlOptionConcurrency      = defineFlagSetValue(crawlFlagSet, "c", "concurrency" /*  */, "Number of pages analyzed concurrently", 4).(*int)
+	crawlOptionHost             = defineFlagSetValue(crawlFlagSet, "t", "host" /*         */, "Host to stay on (default: host of the start URL)", "").(*string)
+	crawlOptionPathPrefix       = defineFlagSetValue(crawlFlagSet, "P", "path-prefix" /*  */, "Only follow URLs whose path starts with this prefix (e.g. /blog/)", "").(*string)
+	crawlOptionMaxKeywords      = defineFlagSetValue(crawlFlagSet, "n", "max-keywords" /* */, "Number of keywords per page", 20).(*int)
+	crawlOptionDedupe           = defineFlagSetValue(crawlFlagSet, "U", "dedupe" /*       */, "Treat pages with the same canonical URL as one page", false).(*bool)
+	crawlOptionPretty           = defineFlagSetValue(crawlFlagSet, "p", "pretty" /*       */, "Format JSON output with indentation", false).(*bool)
+	crawlOptionCommon           = defineCommonOptions(crawlFlagSet)
+)
+
+func init() {
+	formatFlagSetUsage(crawlFlagSet, crawlCommandDescription, &crawlCommandOptionMaxLength, new(bytes.Buffer))
+}
+
+// runCrawl は "crawl" サブコマンドを実行し、終了コードを返します
+func runCrawl(args []string) int {
+	_ = crawlFlagSet.Parse(args)
+	if *crawlOptionUrl == "" && *crawlOptionSitemap == "" {
+		crawlFlagSet.Usage()
+		return 0
+	}
+
+	cfg, err := crawlOptionCommon.buildConfig()
+	if err != nil {
+		handleError(err, "buildConfig")
+		return 1
+	}
+
+	// Ctrl+C でクロールを打ち切る
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := crawler.Options{
+		MaxDepth:        *crawlOptionDepth,
+		MaxPages:        *crawlOptionMaxPages,
+		Concurrency:     *crawlOptionConcurrency,
+		Host:            *crawlOptionHost,
+		PathPrefix:      *crawlOptionPathPrefix,
+		SitemapURL:      *crawlOptionSitemap,
+		MaxKeywords:     *crawlOptionMaxKeywords,
+		DedupeCanonical: *crawlOptionDedupe,
+	}
+	site, err := crawler.Crawl(ctx, *crawlOptionUrl, cfg, opts)
+	if err != nil {
+		handleError(err, "crawler.Crawl")
+		if site == nil {
+			return 1
+		}
+	}
+
+	var jsonData []byte
+	if *crawlOptionPretty {
+		jsonData, err = json.MarshalIndent(site, "", "  ")
+	} else {
+		jsonData, err = json.Marshal(site)
+	}
+	if err != nil {
+		handleError(err, "JSON Marshal")
+		return 1
+	}
+	fmt.Println(string(jsonData))
+	return 0
+}
@@ -2,16 +2,20 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/xshoji/go-keywordminer/internal/fetcher"
 	"github.com/xshoji/go-keywordminer/pkg/analyzer"
 	"github.com/xshoji/go-keywordminer/pkg/config"
 )
@@ -22,13 +26,23 @@ const (
 	TimeFormat          = "2006-01-02 15:04:05.0000 [MST]"
 )
 
+// 終了コード
+const (
+	ExitCodeOK                     = 0
+	ExitCodeError                  = 1
+	ExitCodeHTTPStatus             = 3
+	ExitCodeUnsupportedContentType = 4
+)
+
 var (
 	// Command options ( the -h, --help option is defined by default in the flag package )
-	commandDescription     = "A tool for extracting and analyzing keywords from web pages. \n  Fetches titles, meta tags, and identifies top keywords with their relevance scores."
+	commandDescription     = "A tool for extracting and analyzing keywords from web pages. \n  Fetches titles, meta tags, and identifies top keywords with their relevance scores.\n  Run \"keywordminer batch -h\" to analyze many URLs concurrently, \"keywordminer crawl -h\" to analyze a whole site."
 	commandOptionMaxLength = 0
-	optionUrl              = defineFlagValue("u", "url" /*    */, UsageRequiredPrefix+"URL" /*   */, "").(*string)
+	optionUrl              = defineFlagValue("u", "url" /*    */, "URL to analyze; required unless -f is given (used as the base URL with -f)", "").(*string)
+	optionFile             = defineFlagValue("f", "file" /*   */, "Analyze a local HTML file instead of fetching the URL (\"-\" reads stdin)", "").(*string)
 	optionPretty           = defineFlagValue("p", "pretty" /* */, "Format JSON output with indentation", false).(*bool)
-	optionDetail           = defineFlagValue("d", "detail" /* */, "Output all details including title and meta tags", false).(*bool)
+	optionDetail           = defineFlagValue("d", "detail" /* */, "Output all details including title, meta tags and HTTP response info", false).(*bool)
+	optionCommon           = defineCommonOptions(flag.CommandLine)
 )
 
 func init() {
@@ -38,20 +52,49 @@ func init() {
 // Build:
 // $ GOOS=darwin GOARCH=amd64 go build -ldflags="-s -w" -trimpath ./cmd/keywordminer
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "batch":
+			os.Exit(runBatch(os.Args[2:]))
+		case "crawl":
+			os.Exit(runCrawl(os.Args[2:]))
+		}
+	}
+
 	flag.Parse()
-	if *optionUrl == "" {
+	// -f または引数 "-" が指定された場合はHTTP取得せずにファイル・標準入力を解析
+	source := *optionFile
+	if flag.NArg() > 0 && flag.Arg(0) == "-" {
+		source = "-"
+		// "-" 以降のオプションも解釈する
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+	}
+	if *optionUrl == "" && source == "" {
 		flag.Usage()
 		os.Exit(0)
 	}
 
-	cfg := config.DefaultConfig()
-	anlz, err := analyzer.NewAnalyzer(*optionUrl, cfg)
+	cfg, err := optionCommon.buildConfig()
 	if err != nil {
-		handleError(err, "NewAnalyzer")
+		handleError(err, "buildConfig")
 		os.Exit(1)
 	}
+	// Ctrl+C で解析を中断できるようにする
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var anlz *analyzer.Analyzer
+	if source != "" {
+		anlz, err = newAnalyzerFromSource(source, *optionUrl, cfg)
+	} else {
+		anlz, err = analyzer.NewAnalyzerContext(ctx, *optionUrl, cfg)
+	}
+	if err != nil {
+		handleError(err, "NewAnalyzer")
+		os.Exit(exitCode(err))
+	}
 	// 解析結果を取得
-	result, err := anlz.GetAnalysisResult(20)
+	result, err := anlz.GetAnalysisResultContext(ctx, 20)
 	if err != nil {
 		handleError(err, "GetAnalysisResult")
 		os.Exit(1)
@@ -93,6 +136,21 @@ func main() {
 // Common Utils
 // =======================================
 
+// exitCode はエラーの種類に応じた終了コードを返します
+func exitCode(err error) int {
+	var statusErr *fetcher.ErrHTTPStatus
+	var contentTypeErr *fetcher.ErrUnsupportedContentType
+	switch {
+	case err == nil:
+		return ExitCodeOK
+	case errors.As(err, &statusErr):
+		return ExitCodeHTTPStatus
+	case errors.As(err, &contentTypeErr):
+		return ExitCodeUnsupportedContentType
+	}
+	return ExitCodeError
+}
+
 func handleError(err error, prefixErrMessage string) {
 	if err != nil {
 		fmt.Printf("%s [ERROR %s]: %v\n", time.Now().Format(TimeFormat), prefixErrMessage, err)
@@ -101,22 +159,47 @@ func handleError(err error, prefixErrMessage string) {
 
 // convertKeywords 関数は不要になったため削除
 
+// newAnalyzerFromSource はローカルファイル（"-" の場合は標準入力）のHTMLからAnalyzerを生成します
+func newAnalyzerFromSource(source string, baseURL string, cfg config.Config) (*analyzer.Analyzer, error) {
+	if source == "-" {
+		return analyzer.NewAnalyzerFromReader(os.Stdin, baseURL, cfg)
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open HTML file '%s': %w", source, err)
+	}
+	defer f.Close()
+	return analyzer.NewAnalyzerFromReader(f, baseURL, cfg)
+}
+
 // Helper function for flag
 func defineFlagValue(short, long, description string, defaultValue any) (f any) {
+	return defineFlagSetValue(flag.CommandLine, short, long, description, defaultValue)
+}
+
+// Helper function for flag (for sub commands)
+func defineFlagSetValue(fs *flag.FlagSet, short, long, description string, defaultValue any) (f any) {
 	flagUsage := short + UsageDummy + description
 	switch v := defaultValue.(type) {
 	case string:
-		f = flag.String(short, "", UsageDummy)
-		flag.StringVar(f.(*string), long, v, flagUsage)
+		f = fs.String(short, "", UsageDummy)
+		fs.StringVar(f.(*string), long, v, flagUsage)
 	case int:
-		f = flag.Int(short, 0, UsageDummy)
-		flag.IntVar(f.(*int), long, v, flagUsage)
+		f = fs.Int(short, 0, UsageDummy)
+		fs.IntVar(f.(*int), long, v, flagUsage)
 	case bool:
-		f = flag.Bool(short, false, UsageDummy)
-		flag.BoolVar(f.(*bool), long, v, flagUsage)
+		f = fs.Bool(short, false, UsageDummy)
+		fs.BoolVar(f.(*bool), long, v, flagUsage)
+	case *multiValueFlag:
+		f = v
+		fs.Var(v, short, UsageDummy)
+		fs.Var(v, long, flagUsage)
 	case float64:
-		f = flag.Float64(short, 0.0, UsageDummy)
-		flag.Float64Var(f.(*float64), long, v, flagUsage)
+		f = fs.Float64(short, 0.0, UsageDummy)
+		fs.Float64Var(f.(*float64), long, v, flagUsage)
+	case time.Duration:
+		f = fs.Duration(short, 0, UsageDummy)
+		fs.DurationVar(f.(*time.Duration), long, v, flagUsage)
 	default:
 		panic("unsupported flag type")
 	}
@@ -124,7 +207,16 @@ func defineFlagValue(short, long, description string, defaultValue any) (f any)
 }
 
 func formatUsage(description string, maxLength *int, buffer *bytes.Buffer) {
-	func() { flag.CommandLine.SetOutput(buffer); flag.Usage(); flag.CommandLine.SetOutput(os.Stderr) }()
+	formatFlagSetUsage(flag.CommandLine, description, maxLength, buffer)
+}
+
+func formatFlagSetUsage(fs *flag.FlagSet, description string, maxLength *int, buffer *bytes.Buffer) {
+	func() {
+		_, _ = fmt.Fprintf(buffer, "Usage of %s:\n", fs.Name())
+		fs.SetOutput(buffer)
+		fs.PrintDefaults()
+		fs.SetOutput(os.Stderr)
+	}()
 	usageOption := regexp.MustCompile("(-\\S+)( *\\S*)+\n*\\s+"+UsageDummy+"\n\\s*").ReplaceAllString(buffer.String(), "")
 	re := regexp.MustCompile("\\s(-\\S+)( *\\S*)( *\\S*)+\n\\s+(.+)")
 	usageFirst := strings.Replace(strings.Replace(strings.Split(usageOption, "\n")[0], ":", " [OPTIONS] [-h, --help]", -1), " of ", ": ", -1) + "\n\nDescription:\n  " + description + "\n\nOptions:\n"
@@ -139,5 +231,8 @@ func formatUsage(description string, maxLength *int, buffer *bytes.Buffer) {
 	sort.SliceStable(usageOptionsRep, func(i, j int) bool {
 		return strings.Count(usageOptionsRep[i], UsageRequiredPrefix) > strings.Count(usageOptionsRep[j], UsageRequiredPrefix)
 	})
-	flag.Usage = func() { _, _ = fmt.Fprint(flag.CommandLine.Output(), usageFirst+strings.Join(usageOptionsRep, "")) }
+	fs.Usage = func() { _, _ = fmt.Fprint(fs.Output(), usageFirst+strings.Join(usageOptionsRep, "")) }
+	if fs == flag.CommandLine {
+		flag.Usage = fs.Usage
+	}
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xshoji/go-keywordminer/internal/fetcher"
+	"github.com/xshoji/go-keywordminer/pkg/config"
+)
+
+// commonOptions は各コマンド共通のオプション（ページ取得・解析の設定）
+type commonOptions struct {
+	extractor    *string
+	userAgent    *string
+	headers      *multiValueFlag
+	ignoreRobots *bool
+	rate         *float64
+	retries      *int
+	maxBodyBytes *int
+	proxy        *string
+	caCert       *string
+	insecure     *bool
+	cacheDir     *string
+	cacheTTL     *time.Duration
+	fixtureDir   *string
+	record       *bool
+	hreflang     *string
+	rules        *string
+}
+
+// defineCommonOptions は各コマンド共通のオプションを定義します
+func defineCommonOptions(fs *flag.FlagSet) commonOptions {
+	defaults := config.DefaultConfig()
+	return commonOptions{
+		extractor:    defineFlagSetValue(fs, "e", "extractor" /*     */, "Main content extractor (boilerplate|readability)", config.ContentExtractorBoilerplate).(*string),
+		userAgent:    defineFlagSetValue(fs, "A", "user-agent" /*    */, "User-Agent header sent to the server", "").(*string),
+		headers:      defineFlagSetValue(fs, "H", "header" /*        */, "Extra request header \"Key: Value\" (repeatable)", &multiValueFlag{}).(*multiValueFlag),
+		ignoreRobots: defineFlagSetValue(fs, "R", "ignore-robots" /* */, "Do not check robots.txt (only for your own sites)", false).(*bool),
+		rate:         defineFlagSetValue(fs, "r", "rate" /*          */, "Maximum requests per second per host (0 disables the limit)", defaults.RequestsPerSecond).(*float64),
+		retries:      defineFlagSetValue(fs, "T", "retries" /*       */, "Number of retries on 5xx, 429 and connection errors (0 disables retries)", defaults.Retry.MaxAttempts-1).(*int),
+		maxBodyBytes: defineFlagSetValue(fs, "B", "max-body-bytes" /**/, "Maximum response body size; larger pages are truncated (0 disables the limit)", int(defaults.MaxBodyBytes)).(*int),
+		proxy:        defineFlagSetValue(fs, "x", "proxy" /*         */, "Proxy URL (default: HTTP_PROXY / HTTPS_PROXY environment variables)", "").(*string),
+		caCert:       defineFlagSetValue(fs, "C", "ca-cert" /*       */, "PEM file with additional CA certificates to trust", "").(*string),
+		insecure:     defineFlagSetValue(fs, "k", "insecure" /*      */, "Skip TLS certificate verification (for staging environments)", false).(*bool),
+		cacheDir:     defineFlagSetValue(fs, "D", "cache-dir" /*     */, "Directory for the on-disk HTTP cache (revalidated with ETag / Last-Modified)", "").(*string),
+		cacheTTL:     defineFlagSetValue(fs, "L", "cache-ttl" /*     */, "Use cached pages without revalidation for this long (e.g. 24h)", time.Duration(0)).(*time.Duration),
+		fixtureDir:   defineFlagSetValue(fs, "F", "fixture-dir" /*   */, "Replay responses from this fixture directory instead of the network", "").(*string),
+		record:       defineFlagSetValue(fs, "W", "record" /*        */, "Fetch from the network and record responses into --fixture-dir", false).(*bool),
+		hreflang:     defineFlagSetValue(fs, "l", "hreflang" /*      */, "Analyze the hreflang alternate for this language instead (e.g. en, ja-JP)", "").(*string),
+		rules:        defineFlagSetValue(fs, "S", "rules" /*         */, "JSON file mapping URL patterns to CSS selectors to score or exclude", "").(*string),
+	}
+}
+
+// buildConfig はコマンドオプションから解析設定を生成します
+func (o commonOptions) buildConfig() (config.Config, error) {
+	cfg := config.DefaultConfig()
+	cfg.ContentExtractor = *o.extractor
+	if *o.userAgent != "" {
+		cfg.UserAgent = *o.userAgent
+	}
+	headers, err := parseHeaders(*o.headers)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Headers = headers
+	cfg.IgnoreRobotsTxt = *o.ignoreRobots
+	cfg.RequestsPerSecond = *o.rate
+	cfg.Retry.MaxAttempts = *o.retries + 1
+	cfg.MaxBodyBytes = int64(*o.maxBodyBytes)
+	cfg.ProxyURL = *o.proxy
+	cfg.CACertFile = *o.caCert
+	cfg.InsecureSkipVerify = *o.insecure
+	cfg.CacheDir = *o.cacheDir
+	cfg.CacheTTL = *o.cacheTTL
+	cfg.FixtureDir = *o.fixtureDir
+	cfg.Hreflang = *o.hreflang
+	if *o.rules != "" {
+		rules, err := config.LoadSiteRules(*o.rules)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.SiteRules = rules
+	}
+	cfg.FixtureMode = fetcher.FixtureReplay
+	if *o.record {
+		if cfg.FixtureDir == "" {
+			return cfg, fmt.Errorf("--record requires --fixture-dir")
+		}
+		cfg.FixtureMode = fetcher.FixtureRecord
+	}
+	return cfg, nil
+}
+
+// parseHeaders は "Key: Value" 形式の文字列をヘッダのマップに変換します
+func parseHeaders(values []string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, v := range values {
+		key, value, found := strings.Cut(v, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("Invalid header '%s': expected \"Key: Value\"", v)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// multiValueFlag は複数回指定可能な文字列フラグ
+type multiValueFlag []string
+
+func (m *multiValueFlag) String() string     { return strings.Join(*m, ", ") }
+func (m *multiValueFlag) Set(v string) error { *m = append(*m, v); return nil }
@@ -6,12 +6,14 @@ toolchain go1.23.2
 
 require (
 	github.com/PuerkitoBio/goquery v1.8.1
+	github.com/andybalholm/brotli v1.2.0
+	github.com/andybalholm/cascadia v1.3.3
 	github.com/ikawaha/kagome-dict/ipa v1.0.10
 	github.com/ikawaha/kagome/v2 v2.9.3
+	golang.org/x/net v0.39.0
+	golang.org/x/text v0.24.0
 )
 
 require (
-	github.com/andybalholm/cascadia v1.3.3 // indirect
 	github.com/ikawaha/kagome-dict v1.0.9 // indirect
-	golang.org/x/net v0.39.0 // indirect
 )
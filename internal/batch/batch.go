@@ -0,0 +1,141 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/xshoji/go-keywordminer/internal/fetcher"
+	"github.com/xshoji/go-keywordminer/pkg/analyzer"
+	"github.com/xshoji/go-keywordminer/pkg/config"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
+)
+
+// Options はバッチ解析のオプション
+type Options struct {
+	// Concurrency は同時に解析するURL数（0以下の場合は1）
+	Concurrency int
+	// MaxKeywords はURLごとに出力するキーワード数（0以下の場合はConfigの値）
+	MaxKeywords int
+	// DedupeCanonical がtrueの場合は、canonical URL（ない場合は最終URL）が同じページの結果のうち、最初に完了したもののみ出力します
+	// 結果は完了順に出力されるため、Concurrency が2以上の場合は入力順で後のURLの結果が残ることがあります
+	DedupeCanonical bool
+}
+
+// ReadURLList はURL一覧を1行1URLで読み込みます（空行と "#" で始まる行は無視）
+func ReadURLList(r io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read URL list: %w", err)
+	}
+	return urls, nil
+}
+
+// Run はURL一覧をワーカープールで並行に解析し、完了した順に handle へ結果を渡します
+// 全URLで1つのFetcher（Transport）を共有し、URLごとの失敗は AnalysisResult.Error に設定されます
+// handle は単一のgoroutineから呼ばれます。ctxがキャンセルされた場合は未処理のURLを打ち切り、ctxのエラーを返します
+func Run(ctx context.Context, urls []string, cfg config.Config, opts Options, handle func(*types.AnalysisResult)) error {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	f, err := fetcher.NewFetcher(cfg)
+	if err != nil {
+		return err
+	}
+
+	jobs := make(chan string)
+	results := make(chan *types.AnalysisResult)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range jobs {
+				results <- analyze(ctx, f, url, cfg, opts.MaxKeywords)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, url := range urls {
+			select {
+			case jobs <- url:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	seen := map[string]bool{}
+	for result := range results {
+		if opts.DedupeCanonical {
+			if key := canonicalKey(result); key != "" {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+		}
+		handle(result)
+	}
+	return ctx.Err()
+}
+
+// analyze は1つのURLを解析し、失敗した場合もエラーを含む結果を返します
+func analyze(ctx context.Context, f types.PageFetcher, url string, cfg config.Config, maxKeywords int) *types.AnalysisResult {
+	a, err := analyzer.NewAnalyzerWithFetcher(ctx, url, cfg, f)
+	if err != nil {
+		return &types.AnalysisResult{URL: url, Error: err.Error()}
+	}
+	if maxKeywords <= 0 {
+		maxKeywords = cfg.MaxKeywords
+	}
+	result, err := a.GetAnalysisResultContext(ctx, maxKeywords)
+	if err != nil {
+		if result == nil {
+			result = &types.AnalysisResult{}
+		}
+		result.Error = err.Error()
+	}
+	// 入力したURLで結果を突き合わせられるよう、リダイレクト後ではなく元のURLを設定
+	result.URL = url
+	return result
+}
+
+// canonicalKey は重複排除に使うページのURL（canonical URL、ない場合はリダイレクト後の最終URL）を
+// クロールと同じ方法で正規化して返します。失敗した結果は重複とみなさないため空文字を返します
+func canonicalKey(result *types.AnalysisResult) string {
+	if result.Error != "" {
+		return ""
+	}
+	key := result.URL
+	if result.Canonical != "" {
+		key = result.Canonical
+	} else if result.Fetch != nil {
+		key = result.Fetch.FinalURL
+	}
+	normalized, err := utils.NormalizeURL(key)
+	if err != nil {
+		return key
+	}
+	return normalized
+}
@@ -0,0 +1,126 @@
+package batch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+)
+
+func TestReadURLList(t *testing.T) {
+	urls, err := ReadURLList(strings.NewReader("https://a.example\n\n# comment\n  https://b.example  \n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(urls) != 2 || urls[0] != "https://a.example" || urls[1] != "https://b.example" {
+		t.Errorf("unexpected urls: %v", urls)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Page " + strings.TrimPrefix(r.URL.Path, "/") + "</title></head></html>"))
+	}))
+	defer ts.Close()
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	urls := []string{ts.URL + "/alpha", ts.URL + "/beta", ts.URL + "/gamma", closed.URL}
+	results := map[string]*types.AnalysisResult{}
+	err := Run(context.Background(), urls, config.DefaultConfig(), Options{Concurrency: 3, MaxKeywords: 5}, func(r *types.AnalysisResult) {
+		results[r.URL] = r
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != len(urls) {
+		t.Fatalf("expected %d results, got %d", len(urls), len(results))
+	}
+	if r := results[ts.URL+"/beta"]; r.Title != "Page beta" || r.Error != "" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+	if r := results[closed.URL]; r.Error == "" {
+		t.Errorf("expected error for unreachable URL, got %+v", r)
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	count := 0
+	err := Run(ctx, []string{"http://example.invalid/"}, config.DefaultConfig(), Options{}, func(r *types.AnalysisResult) {
+		count++
+	})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if count > 1 {
+		t.Errorf("expected at most 1 result, got %d", count)
+	}
+}
+
+func TestRun_DedupeCanonical(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		canonical := "/article"
+		if r.URL.Path == "/other" {
+			canonical = "/other"
+		}
+		w.Write([]byte(`<html><head><title>Article</title><link rel="canonical" href="` + canonical + `"></head></html>`))
+	}))
+	defer ts.Close()
+
+	urls := []string{ts.URL + "/article", ts.URL + "/article?utm_source=feed", ts.URL + "/amp/article", ts.URL + "/other"}
+	var results []*types.AnalysisResult
+	err := Run(context.Background(), urls, config.DefaultConfig(), Options{Concurrency: 2, DedupeCanonical: true}, func(r *types.AnalysisResult) {
+		results = append(results, r)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	canonicals := map[string]int{}
+	for _, r := range results {
+		canonicals[r.Canonical]++
+	}
+	if len(results) != 2 || canonicals[ts.URL+"/article"] != 1 || canonicals[ts.URL+"/other"] != 1 {
+		t.Errorf("expected one result per canonical URL, got %+v", results)
+	}
+}
+
+func TestRun_DedupeCanonical_FirstCompleted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var canonical string
+		switch r.URL.Path {
+		case "/slow":
+			// 入力順で最初のURLでも、後に完了した結果は重複として除かれる
+			time.Sleep(200 * time.Millisecond)
+			canonical = "/article#intro"
+		case "/upper":
+			canonical = "HTTP://" + r.Host + "/article"
+		default:
+			canonical = r.URL.Path
+		}
+		w.Write([]byte(`<html><head><title>` + r.URL.Path + `</title><link rel="canonical" href="` + canonical + `"></head></html>`))
+	}))
+	defer ts.Close()
+
+	// canonical URLはスキームの大文字小文字・フラグメントの違いを正規化して比較する
+	urls := []string{ts.URL + "/slow", ts.URL + "/upper", ts.URL + "/other"}
+	// 同一ホストへのリクエスト間隔の制御で完了順が入力順にならないよう無効にする
+	cfg := config.DefaultConfig()
+	cfg.RequestsPerSecond = 0
+	got := map[string]bool{}
+	err := Run(context.Background(), urls, cfg, Options{Concurrency: 3, DedupeCanonical: true}, func(r *types.AnalysisResult) {
+		got[r.URL] = true
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || !got[ts.URL+"/upper"] || !got[ts.URL+"/other"] {
+		t.Errorf("expected the first completed result per canonical URL, got %v", got)
+	}
+}
@@ -0,0 +1,248 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xshoji/go-keywordminer/internal/fetcher"
+	"github.com/xshoji/go-keywordminer/pkg/analyzer"
+	"github.com/xshoji/go-keywordminer/pkg/config"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
+)
+
+const (
+	// DefaultMaxPages は MaxPages 未指定時に解析する最大ページ数
+	DefaultMaxPages = 100
+	// DefaultMaxSiteKeywords は MaxSiteKeywords 未指定時のサイト全体のキーワード数
+	DefaultMaxSiteKeywords = 50
+)
+
+// Options はクロールのオプション
+type Options struct {
+	// MaxDepth はシードURLからリンクを辿る深さ（0の場合はシードのみ）
+	MaxDepth int
+	// MaxPages は解析する最大ページ数（0以下の場合は DefaultMaxPages）
+	MaxPages int
+	// Concurrency は同時に解析するページ数（0以下の場合は1）
+	Concurrency int
+	// Host は対象とするホスト（空の場合はシードURLまたはサイトマップURLのホスト）
+	Host string
+	// PathPrefix は対象とするパスの接頭辞（例: "/blog/"）
+	PathPrefix string
+	// SitemapURL はクロールの起点に加えるsitemap.xml（サイトマップインデックス可）
+	SitemapURL string
+	// MaxKeywords はページごとのキーワード数（0以下の場合はConfigの値）
+	MaxKeywords int
+	// MaxSiteKeywords はサイト全体で集計するキーワード数（0以下の場合は DefaultMaxSiteKeywords）
+	MaxSiteKeywords int
+	// DedupeCanonical がtrueの場合は、canonical URL が解析済みのページと同じページを1ページとして扱います
+	DedupeCanonical bool
+}
+
+// pageResult は1ページの解析結果とページ内リンク
+type pageResult struct {
+	result   *types.AnalysisResult
+	finalURL string
+	// canonical は正規化した canonical URL（ない場合は空文字）
+	canonical string
+	links     []string
+}
+
+// Crawl はシードURL（およびサイトマップ）からリンクを辿ってページを解析し、
+// ページごとの結果とサイト全体のキーワード集計を返します
+// 同一ページは正規化したURLで重複排除し、Host・PathPrefix の範囲外のリンクは辿りません
+func Crawl(ctx context.Context, seedURL string, cfg config.Config, opts Options) (*types.SiteAnalysisResult, error) {
+	maxPages := opts.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+	f, err := fetcher.NewFetcher(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	origin := seedURL
+	if origin == "" {
+		origin = opts.SitemapURL
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil || originURL.Host == "" {
+		return nil, fmt.Errorf("Invalid crawl start URL '%s'", origin)
+	}
+	sc := scope{host: strings.ToLower(originURL.Hostname()), pathPrefix: opts.PathPrefix}
+	if opts.Host != "" {
+		sc.host = strings.ToLower(opts.Host)
+	}
+
+	visited := map[string]bool{}
+	var frontier []string
+	enqueue := func(raw string) {
+		normalized, err := utils.NormalizeURL(raw)
+		if err != nil || visited[normalized] {
+			return
+		}
+		u, _ := url.Parse(normalized)
+		if !sc.contains(u) {
+			return
+		}
+		visited[normalized] = true
+		frontier = append(frontier, normalized)
+	}
+
+	if seedURL != "" {
+		enqueue(seedURL)
+	}
+	if opts.SitemapURL != "" {
+		sitemapOpts := f.Options
+		// サイトマップはXML（gzip圧縮の場合もある）のためContent-Typeは確認しない
+		sitemapOpts.ContentTypes = nil
+		pages, err := fetchSitemapURLs(ctx, opts.SitemapURL, sitemapOpts, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, page := range pages {
+			enqueue(page)
+		}
+	}
+
+	site := &types.SiteAnalysisResult{URL: origin}
+	crawled := map[string]bool{}
+	for depth := 0; depth <= opts.MaxDepth && len(frontier) > 0 && len(site.Pages) < maxPages; depth++ {
+		level := frontier
+		frontier = nil
+		if remaining := maxPages - len(site.Pages); len(level) > remaining {
+			level = level[:remaining]
+		}
+
+		for _, page := range crawlLevel(ctx, f, level, cfg, opts) {
+			// リダイレクトで同じページに行き着いた場合（DedupeCanonical の場合は canonical URL が同じ場合も）は1ページとして扱う
+			keys := []string{page.finalURL}
+			if opts.DedupeCanonical && page.canonical != "" {
+				keys = append(keys, page.canonical)
+			}
+			duplicate := false
+			for _, key := range keys {
+				duplicate = duplicate || crawled[key]
+				crawled[key] = true
+				visited[key] = true
+			}
+			if duplicate {
+				continue
+			}
+			site.Pages = append(site.Pages, *page.result)
+			if depth < opts.MaxDepth {
+				for _, link := range page.links {
+					enqueue(link)
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return site, err
+		}
+	}
+
+	maxSiteKeywords := opts.MaxSiteKeywords
+	if maxSiteKeywords <= 0 {
+		maxSiteKeywords = DefaultMaxSiteKeywords
+	}
+	site.Keywords = AggregateKeywords(site.Pages, maxSiteKeywords)
+	return site, nil
+}
+
+// crawlLevel は同じ深さのURLを並行に解析し、入力と同じ順序で結果を返します
+func crawlLevel(ctx context.Context, f types.PageFetcher, urls []string, cfg config.Config, opts Options) []pageResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	results := make([]pageResult, len(urls))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = crawlPage(ctx, f, u, cfg, opts.MaxKeywords)
+		}(i, u)
+	}
+	wg.Wait()
+	return results
+}
+
+// crawlPage は1ページを解析し、失敗した場合もエラーを含む結果を返します
+func crawlPage(ctx context.Context, f types.PageFetcher, pageURL string, cfg config.Config, maxKeywords int) pageResult {
+	a, err := analyzer.NewAnalyzerWithFetcher(ctx, pageURL, cfg, f)
+	if err != nil {
+		return pageResult{result: &types.AnalysisResult{URL: pageURL, Error: err.Error()}, finalURL: pageURL}
+	}
+	if maxKeywords <= 0 {
+		maxKeywords = cfg.MaxKeywords
+	}
+	result, err := a.GetAnalysisResultContext(ctx, maxKeywords)
+	if err != nil {
+		if result == nil {
+			result = &types.AnalysisResult{URL: a.URL}
+		}
+		result.Error = err.Error()
+	}
+	finalURL, err := utils.NormalizeURL(a.URL)
+	if err != nil {
+		finalURL = pageURL
+	}
+	canonical, err := utils.NormalizeURL(result.Canonical)
+	if err != nil {
+		canonical = ""
+	}
+	return pageResult{result: result, finalURL: finalURL, canonical: canonical, links: a.FetchLinks()}
+}
+
+// AggregateKeywords はページごとのキーワードをサイト全体で集計します
+// スコアは各ページのスコアの合計で、同点の場合は出現ページ数の多い順に並べます
+func AggregateKeywords(pages []types.AnalysisResult, limit int) []types.SiteKeyword {
+	index := map[string]int{}
+	var keywords []types.SiteKeyword
+	for _, page := range pages {
+		for _, k := range page.Keywords {
+			key := strings.ToLower(k.Keyword)
+			i, ok := index[key]
+			if !ok {
+				i = len(keywords)
+				index[key] = i
+				keywords = append(keywords, types.SiteKeyword{Keyword: k.Keyword})
+			}
+			keywords[i].Score += k.Score
+			keywords[i].Pages++
+		}
+	}
+	sort.SliceStable(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Pages > keywords[j].Pages
+	})
+	if limit > 0 && len(keywords) > limit {
+		keywords = keywords[:limit]
+	}
+	return keywords
+}
+
+// scope はクロール対象の範囲
+type scope struct {
+	host       string
+	pathPrefix string
+}
+
+// contains はURLがクロール対象の範囲内か判定します
+func (s scope) contains(u *url.URL) bool {
+	if strings.ToLower(u.Hostname()) != s.host {
+		return false
+	}
+	return s.pathPrefix == "" || strings.HasPrefix(u.Path, s.pathPrefix)
+}
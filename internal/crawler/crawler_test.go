@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+)
+
+func newTestSite() *httptest.Server {
+	pages := map[string]string{
+		"/":       `<html><head><title>Home gopher</title></head><body><a href="/a">A</a><a href="/b#top">B</a><a href="b">B again</a><a href="https://other.example/">Ext</a></body></html>`,
+		"/a":      `<html><head><title>Alpha gopher</title></head><body><a href="/blog/c">C</a></body></html>`,
+		"/b":      `<html><head><title>Beta</title></head><body><a href="/">Home</a></body></html>`,
+		"/blog/c": `<html><head><title>Gamma</title></head><body></body></html>`,
+		"/blog/d": `<html><head><title>Delta</title></head><body></body></html>`,
+	}
+	mux := http.NewServeMux()
+	var ts *httptest.Server
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>` + ts.URL + `/sitemap-blog.xml</loc></sitemap></sitemapindex>`))
+	})
+	mux.HandleFunc("/sitemap-blog.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>` + ts.URL + `/blog/d</loc></url></urlset>`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := pages[r.URL.Path]; ok {
+			w.Write([]byte(body))
+			return
+		}
+		http.NotFound(w, r)
+	})
+	ts = httptest.NewServer(mux)
+	return ts
+}
+
+func crawledPaths(t *testing.T, site *types.SiteAnalysisResult, base string) []string {
+	t.Helper()
+	var paths []string
+	for _, p := range site.Pages {
+		paths = append(paths, p.URL[len(base):])
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestCrawl_Depth(t *testing.T) {
+	ts := newTestSite()
+	defer ts.Close()
+
+	site, err := Crawl(context.Background(), ts.URL+"/", config.DefaultConfig(), Options{MaxDepth: 1, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paths := crawledPaths(t, site, ts.URL)
+	expected := []string{"/", "/a", "/b"}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, paths)
+		}
+	}
+	if len(site.Keywords) == 0 || site.Keywords[0].Keyword != "gopher" || site.Keywords[0].Pages != 2 {
+		t.Errorf("expected 'gopher' on 2 pages as top site keyword, got %+v", site.Keywords)
+	}
+}
+
+func TestCrawl_SitemapAndPathPrefix(t *testing.T) {
+	ts := newTestSite()
+	defer ts.Close()
+
+	opts := Options{MaxDepth: 2, PathPrefix: "/blog/", SitemapURL: ts.URL + "/sitemap.xml"}
+	site, err := Crawl(context.Background(), "", config.DefaultConfig(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paths := crawledPaths(t, site, ts.URL)
+	if len(paths) != 1 || paths[0] != "/blog/d" {
+		t.Errorf("expected only /blog/d, got %v", paths)
+	}
+}
+
+func TestCrawl_MaxPages(t *testing.T) {
+	ts := newTestSite()
+	defer ts.Close()
+
+	site, err := Crawl(context.Background(), ts.URL, config.DefaultConfig(), Options{MaxDepth: 5, MaxPages: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(site.Pages) != 2 {
+		t.Errorf("expected 2 pages, got %d", len(site.Pages))
+	}
+}
+
+func TestAggregateKeywords(t *testing.T) {
+	pages := []types.AnalysisResult{
+		{Keywords: []types.KeywordWithScore{{Keyword: "Go", Score: 5}, {Keyword: "rust", Score: 3}}},
+		{Keywords: []types.KeywordWithScore{{Keyword: "go", Score: 2}, {Keyword: "zig", Score: 7}}},
+	}
+	keywords := AggregateKeywords(pages, 2)
+	if len(keywords) != 2 {
+		t.Fatalf("expected 2 keywords, got %+v", keywords)
+	}
+	if keywords[0].Keyword != "Go" || keywords[0].Score != 7 || keywords[0].Pages != 2 {
+		t.Errorf("unexpected first keyword: %+v", keywords[0])
+	}
+	if keywords[1].Keyword != "zig" {
+		t.Errorf("unexpected second keyword: %+v", keywords[1])
+	}
+}
+
+func TestCrawl_DedupeCanonical(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Home</title></head><body><a href="/post?ref=top">Post</a><a href="/post">Post</a></body></html>`))
+	})
+	mux.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Post</title><link rel="canonical" href="/post"></head></html>`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		dedupe bool
+		pages  int
+	}{{false, 3}, {true, 2}} {
+		site, err := Crawl(context.Background(), ts.URL+"/", config.DefaultConfig(), Options{MaxDepth: 1, DedupeCanonical: tc.dedupe})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(site.Pages) != tc.pages {
+			t.Errorf("DedupeCanonical=%v: expected %d pages, got %v", tc.dedupe, tc.pages, crawledPaths(t, site, ts.URL))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xshoji/go-keywordminer/internal/fetcher"
+)
+
+// maxSitemapIndexDepth はサイトマップインデックスを辿る最大の深さ
+const maxSitemapIndexDepth = 3
+
+// sitemapDocument は urlset / sitemapindex の両方を表すXML構造
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// ParseSitemap はsitemap.xmlの内容を解析し、ページURLと子サイトマップのURLを返します
+// gzip圧縮されたサイトマップ（.xml.gz）も扱えます
+func ParseSitemap(body []byte) (pages []string, sitemaps []string, err error) {
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to decompress sitemap: %w", err)
+		}
+		defer zr.Close()
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, nil, fmt.Errorf("Failed to decompress sitemap: %w", err)
+		}
+	}
+
+	var doc sitemapDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, nil, fmt.Errorf("Failed to parse sitemap: %w", err)
+	}
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			pages = append(pages, loc)
+		}
+	}
+	for _, sm := range doc.Sitemaps {
+		if loc := strings.TrimSpace(sm.Loc); loc != "" {
+			sitemaps = append(sitemaps, loc)
+		}
+	}
+	return pages, sitemaps, nil
+}
+
+// fetchSitemapURLs はサイトマップ（インデックスの場合は子サイトマップも含む）からページURLを集めます
+func fetchSitemapURLs(ctx context.Context, sitemapURL string, opts fetcher.RequestOptions, depth int) ([]string, error) {
+	res, err := fetcher.FetchURLContext(ctx, sitemapURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	pages, sitemaps, err := ParseSitemap(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read sitemap '%s': %w", sitemapURL, err)
+	}
+	if depth >= maxSitemapIndexDepth {
+		return pages, nil
+	}
+	for _, child := range sitemaps {
+		childPages, err := fetchSitemapURLs(ctx, child, opts, depth+1)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// 一部の子サイトマップが取得できなくても他のサイトマップは利用する
+			continue
+		}
+		pages = append(pages, childPages...)
+	}
+	return pages, nil
+}
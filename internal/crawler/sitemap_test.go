@@ -0,0 +1,38 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestParseSitemap(t *testing.T) {
+	urlset := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://example.com/a </loc><lastmod>2024-01-01</lastmod></url>
+  <url><loc>https://example.com/b</loc></url>
+</urlset>`)
+	pages, sitemaps, err := ParseSitemap(urlset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pages) != 2 || pages[0] != "https://example.com/a" || len(sitemaps) != 0 {
+		t.Errorf("unexpected result: %v, %v", pages, sitemaps)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>https://example.com/s1.xml</loc></sitemap></sitemapindex>`))
+	zw.Close()
+	pages, sitemaps, err = ParseSitemap(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pages) != 0 || len(sitemaps) != 1 || sitemaps[0] != "https://example.com/s1.xml" {
+		t.Errorf("unexpected result: %v, %v", pages, sitemaps)
+	}
+
+	if _, _, err := ParseSitemap([]byte("not xml")); err == nil {
+		t.Error("expected error for invalid sitemap")
+	}
+}
@@ -0,0 +1,192 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xshoji/go-keywordminer/pkg/types"
+)
+
+// キャッシュの利用状況（FetchResult.CacheStatus）
+const (
+	// CacheMiss はキャッシュになくサーバーから取得した場合
+	CacheMiss = "miss"
+	// CacheHit は有効期限内のキャッシュをリクエストせずに使用した場合
+	CacheHit = "hit"
+	// CacheRevalidated は条件付きGETで304が返り、キャッシュを使用した場合
+	CacheRevalidated = "revalidated"
+)
+
+// DiskCache はURLごとのレスポンスボディと検証子（ETag/Last-Modified）をディスクに保存するキャッシュ
+// キーにはURLに加えてUser-Agentと追加ヘッダを含めるため、言語などのヘッダが異なる取得結果は別に保存されます
+// Cache-Control: no-store / private のレスポンスは保存せず、no-cache の場合はTTL内でも毎回再検証します
+// 複数のgoroutine・プロセスから同時に使用できます
+type DiskCache struct {
+	// Dir はキャッシュファイルを保存するディレクトリ（存在しない場合は作成します）
+	Dir string
+	// TTL はリクエストせずにキャッシュを使用する期間（0以下の場合は毎回条件付きGETで検証）
+	TTL time.Duration
+}
+
+// cacheEntry はキャッシュファイルの内容
+type cacheEntry struct {
+	// Key はURLとリクエストヘッダから生成したキャッシュのキー
+	Key         string           `json:"key"`
+	URL         string           `json:"url"`
+	FinalURL    string           `json:"final_url"`
+	StatusCode  int              `json:"status_code"`
+	Header      http.Header      `json:"header"`
+	Redirects   []types.Redirect `json:"redirects,omitempty"`
+	Body        []byte           `json:"body"`
+	StoredAt    time.Time        `json:"stored_at"`
+	ContentType string           `json:"content_type,omitempty"`
+}
+
+// NewDiskCache はディレクトリと有効期限を指定してDiskCacheを生成します
+func NewDiskCache(dir string, ttl time.Duration) *DiskCache {
+	return &DiskCache{Dir: dir, TTL: ttl}
+}
+
+// credentialHeaders は認証情報を含むリクエストヘッダ（指定された場合はキャッシュを使用しない）
+var credentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// hasCredentialHeaders は追加ヘッダに認証情報が含まれるか判定します
+func hasCredentialHeaders(headers map[string]string) bool {
+	for key := range headers {
+		for _, credential := range credentialHeaders {
+			if strings.EqualFold(key, credential) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cacheKey はURL・User-Agent・追加ヘッダからキャッシュのキーを生成します
+// （Accept-Language などのヘッダで内容が変わるレスポンスを取り違えないため）
+func cacheKey(url string, userAgent string, headers map[string]string) string {
+	lines := make([]string, 0, len(headers))
+	for key, value := range headers {
+		lines = append(lines, strings.ToLower(key)+": "+value)
+	}
+	sort.Strings(lines)
+	return url + "\n" + "user-agent: " + userAgent + "\n" + strings.Join(lines, "\n")
+}
+
+// path はキーに対応するキャッシュファイルのパスを返します
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load はキーに対応するキャッシュを読み込みます（存在しない・壊れている場合はnil）
+func (c *DiskCache) load(key string) *cacheEntry {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return nil
+	}
+	return &entry
+}
+
+// remove はキーに対応するキャッシュを削除します
+func (c *DiskCache) remove(key string) {
+	_ = os.Remove(c.path(key))
+}
+
+// store はキャッシュを書き込みます（読み込み中のプロセスが壊れたファイルを読まないよう一時ファイルからリネーム）
+func (c *DiskCache) store(entry *cacheEntry) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("Failed to create cache directory '%s': %w", c.Dir, err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("Failed to encode cache entry for URL '%s': %w", entry.URL, err)
+	}
+	tmp, err := os.CreateTemp(c.Dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("Failed to write cache entry for URL '%s': %w", entry.URL, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Failed to write cache entry for URL '%s': %w", entry.URL, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Failed to write cache entry for URL '%s': %w", entry.URL, err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(entry.Key)); err != nil {
+		return fmt.Errorf("Failed to write cache entry for URL '%s': %w", entry.URL, err)
+	}
+	return nil
+}
+
+// fresh はキャッシュが有効期限内か判定します（Cache-Control: no-cache の場合は常に再検証が必要）
+func (c *DiskCache) fresh(entry *cacheEntry, now time.Time) bool {
+	return c.TTL > 0 && now.Sub(entry.StoredAt) < c.TTL && !cacheControl(entry.Header)["no-cache"]
+}
+
+// storable はレスポンスをキャッシュに保存してよいか判定します（Cache-Control: no-store / private は保存しない）
+func storable(header http.Header) bool {
+	directives := cacheControl(header)
+	return !directives["no-store"] && !directives["private"]
+}
+
+// cacheControl はCache-Controlヘッダのディレクティブ名（小文字）を返します
+func cacheControl(header http.Header) map[string]bool {
+	directives := map[string]bool{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(directive, "=")
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				directives[name] = true
+			}
+		}
+	}
+	return directives
+}
+
+// setValidators は条件付きGETのヘッダ（If-None-Match / If-Modified-Since）を設定します
+func (e *cacheEntry) setValidators(req *http.Request) {
+	if etag := e.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := e.Header.Get("Last-Modified"); lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// updateValidators は304レスポンスで更新された検証子とCache-Controlをキャッシュに反映します
+func (e *cacheEntry) updateValidators(header http.Header) {
+	for _, key := range []string{"ETag", "Last-Modified", "Cache-Control"} {
+		if value := header.Get(key); value != "" {
+			e.Header.Set(key, value)
+		}
+	}
+}
+
+// result はキャッシュの内容を取得結果として返します
+func (e *cacheEntry) result(status string, attempts int, duration time.Duration) *FetchResult {
+	return &FetchResult{
+		URL:         e.FinalURL,
+		ContentType: e.ContentType,
+		Body:        e.Body,
+		Attempts:    attempts,
+		StatusCode:  e.StatusCode,
+		Header:      e.Header,
+		Redirects:   e.Redirects,
+		Duration:    duration,
+		CacheStatus: status,
+	}
+}
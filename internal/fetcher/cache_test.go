@@ -0,0 +1,206 @@
+package fetcher
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchURLWithOptions_CacheRevalidation(t *testing.T) {
+	var requests, notModified int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body>cached page</body></html>"))
+	}))
+	defer ts.Close()
+
+	opts := RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, ContentTypes: HTMLContentTypes, Cache: NewDiskCache(t.TempDir(), 0)}
+
+	// 初回はサーバーから取得して保存
+	res, err := FetchURLWithOptions(ts.URL, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.CacheStatus != CacheMiss {
+		t.Errorf("expected cache miss, got '%s'", res.CacheStatus)
+	}
+
+	// TTLが0の場合は条件付きGETで再検証し、304ならキャッシュを使う
+	res, err = FetchURLWithOptions(ts.URL, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.CacheStatus != CacheRevalidated || atomic.LoadInt32(&notModified) != 1 {
+		t.Errorf("expected revalidated cache, got '%s' (304 responses: %d)", res.CacheStatus, notModified)
+	}
+	if string(res.Body) != "<html><body>cached page</body></html>" || res.ContentType != "text/html" || res.URL != ts.URL {
+		t.Errorf("unexpected cached result: %+v", res)
+	}
+
+	// TTL内はリクエストしない
+	opts.Cache.TTL = time.Hour
+	before := atomic.LoadInt32(&requests)
+	res, err = FetchURLWithOptions(ts.URL, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.CacheStatus != CacheHit || atomic.LoadInt32(&requests) != before {
+		t.Errorf("expected cache hit without request, got '%s'", res.CacheStatus)
+	}
+}
+
+func TestFetchURLWithOptions_CacheLastModified(t *testing.T) {
+	lastModified := "Mon, 01 Jan 2024 00:00:00 GMT"
+	var conditional int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte("<html><body>page</body></html>"))
+	}))
+	defer ts.Close()
+
+	opts := RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, Cache: NewDiskCache(t.TempDir(), 0)}
+	for i := 0; i < 2; i++ {
+		if _, err := FetchURLWithOptions(ts.URL, opts); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if atomic.LoadInt32(&conditional) != 1 {
+		t.Errorf("expected one conditional request, got %d", conditional)
+	}
+}
+
+func TestDiskCache_LoadMissing(t *testing.T) {
+	c := NewDiskCache(t.TempDir(), time.Hour)
+	if entry := c.load("https://example.com/"); entry != nil {
+		t.Errorf("expected nil entry, got %+v", entry)
+	}
+	if err := c.store(&cacheEntry{Key: "https://example.com/", URL: "https://example.com/", Header: http.Header{}, StoredAt: time.Now()}); err != nil {
+		t.Fatalf("store error: %v", err)
+	}
+	if entry := c.load("https://example.com/"); entry == nil || !c.fresh(entry, time.Now()) {
+		t.Errorf("expected fresh entry, got %+v", entry)
+	}
+	if c.fresh(c.load("https://example.com/"), time.Now().Add(2*time.Hour)) {
+		t.Error("expected expired entry")
+	}
+}
+
+func TestFetchURLWithOptions_CacheControl(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", r.URL.Query().Get("cc"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("<html><body>page</body></html>"))
+	}))
+	defer ts.Close()
+
+	opts := RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, Cache: NewDiskCache(t.TempDir(), time.Hour)}
+	cases := []struct {
+		cacheControl string
+		second       string
+	}{
+		// no-store / private は保存しない
+		{"no-store", CacheMiss},
+		{"private, max-age=60", CacheMiss},
+		// no-cache はTTL内でも再検証する
+		{"no-cache", CacheRevalidated},
+		{"public, max-age=60", CacheHit},
+	}
+	for _, tc := range cases {
+		u := ts.URL + "/?cc=" + url.QueryEscape(tc.cacheControl)
+		for i, expected := range []string{CacheMiss, tc.second} {
+			res, err := FetchURLWithOptions(u, opts)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if res.CacheStatus != expected {
+				t.Errorf("Cache-Control '%s' request %d: expected '%s', got '%s'", tc.cacheControl, i+1, expected, res.CacheStatus)
+			}
+		}
+	}
+}
+
+func TestFetchURLWithOptions_CacheKeyHeaders(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("<html><body>" + r.Header.Get("Accept-Language") + "</body></html>"))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	fetch := func(headers map[string]string) *FetchResult {
+		t.Helper()
+		res, err := FetchURLWithOptions(ts.URL, RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, Headers: headers, Cache: NewDiskCache(dir, time.Hour)})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return res
+	}
+
+	// Accept-Language が異なる取得結果は取り違えない
+	fetch(map[string]string{"Accept-Language": "ja"})
+	if res := fetch(map[string]string{"Accept-Language": "en"}); res.CacheStatus != CacheMiss || string(res.Body) != "<html><body>en</body></html>" {
+		t.Errorf("expected separate cache entry per Accept-Language, got '%s' %s", res.CacheStatus, res.Body)
+	}
+	if res := fetch(map[string]string{"Accept-Language": "ja"}); res.CacheStatus != CacheHit || string(res.Body) != "<html><body>ja</body></html>" {
+		t.Errorf("expected cache hit for the same Accept-Language, got '%s' %s", res.CacheStatus, res.Body)
+	}
+
+	// 認証情報を含むリクエストはキャッシュを使用しない
+	before := atomic.LoadInt32(&requests)
+	for i := 0; i < 2; i++ {
+		if res := fetch(map[string]string{"Authorization": "Bearer secret"}); res.CacheStatus != "" {
+			t.Errorf("expected cache to be bypassed with credentials, got '%s'", res.CacheStatus)
+		}
+	}
+	if atomic.LoadInt32(&requests)-before != 2 {
+		t.Errorf("expected every authenticated request to reach the server")
+	}
+}
+
+func TestFetchURLWithOptions_CacheTruncated(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>0123456789</body></html>"))
+	}))
+	defer ts.Close()
+
+	opts := RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, Cache: NewDiskCache(t.TempDir(), time.Hour), MaxBodyBytes: 16}
+	if res, err := FetchURLWithOptions(ts.URL, opts); err != nil || !res.Truncated {
+		t.Fatalf("expected truncated body, got %v", err)
+	}
+
+	// 切り詰めたボディはキャッシュされず、上限を変えた取得に影響しない
+	opts.FailOnLargeBody = true
+	var tooLarge *ErrBodyTooLarge
+	if _, err := FetchURLWithOptions(ts.URL, opts); !errors.As(err, &tooLarge) {
+		t.Errorf("expected ErrBodyTooLarge, got %v", err)
+	}
+	opts.MaxBodyBytes, opts.FailOnLargeBody = 0, false
+	res, err := FetchURLWithOptions(ts.URL, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.CacheStatus != CacheMiss || res.Truncated || len(res.Body) != 36 {
+		t.Errorf("expected full body from the server, got %s %d bytes", res.CacheStatus, len(res.Body))
+	}
+}
@@ -0,0 +1,140 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+)
+
+// defaultTransport はプロキシ・TLSの指定がない場合にプロセス内で共有するTransport
+var defaultTransport = newBaseTransport()
+
+// newBaseTransport は接続を使い回すための共通設定のTransportを生成します
+func newBaseTransport() *http.Transport {
+	return &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		// 圧縮の展開は decodeBody で行う
+		DisableCompression: true,
+	}
+}
+
+// newTransport はConfigのプロキシ・TLS設定を反映したTransportを返します
+// 設定がない場合は defaultTransport を共有します
+func newTransport(cfg config.Config) (*http.Transport, error) {
+	if cfg.ProxyURL == "" && cfg.CACertFile == "" && !cfg.InsecureSkipVerify {
+		return defaultTransport, nil
+	}
+	t := newBaseTransport()
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("Invalid proxy URL '%s'", cfg.ProxyURL)
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read CA certificate file '%s': %w", cfg.CACertFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No valid certificates found in '%s'", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+// Fetcher は1つのTransportを共有してページを取得します（types.ContextPageFetcher を実装）
+// 複数のgoroutineから同時に使用できます
+type Fetcher struct {
+	// Options は各リクエストに適用するオプション（Client は NewFetcher で生成したもの）
+	Options RequestOptions
+}
+
+var _ types.ContextPageFetcher = (*Fetcher)(nil)
+
+// NewFetcher はConfigのタイムアウト・プロキシ・TLS・リクエスト設定でFetcherを生成します
+// FixtureDir が指定されている場合はフィクスチャを記録・再生します
+func NewFetcher(cfg config.Config) (*Fetcher, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewFetcherWithClient(cfg, &http.Client{Timeout: cfg.Timeout, Transport: transport})
+}
+
+// NewFetcherWithClient は指定したHTTPクライアントでページを取得するFetcherを生成します
+// プロキシ・TLSはクライアントのTransportに従い、nilの場合は NewFetcher と同じくConfigから生成します
+func NewFetcherWithClient(cfg config.Config, client *http.Client) (*Fetcher, error) {
+	if client == nil {
+		return NewFetcher(cfg)
+	}
+	opts := NewRequestOptions(cfg)
+	opts.Client = client
+	// robots.txtとリクエスト間隔はFetcherごとに保持し、フィクスチャの内容が他のFetcherに影響しないようにする
+	opts.Robots = NewRobotsCache()
+	opts.HostLimiter = NewHostLimiter()
+	f := &Fetcher{Options: opts}
+	if cfg.FixtureDir != "" {
+		if err := f.useFixtures(cfg.FixtureDir, cfg.FixtureMode); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Fetch は指定URLのレスポンスボディを取得します（timeout が0以下の場合はClientのタイムアウトのみ）
+func (f *Fetcher) Fetch(url string, timeout time.Duration) ([]byte, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	res, err := f.FetchContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// FetchContext はcontextによるキャンセル・期限付きでページを取得し、Content-Typeなどを含む結果を返します
+func (f *Fetcher) FetchContext(ctx context.Context, url string) (*FetchResult, error) {
+	return FetchURLContext(ctx, url, f.Options)
+}
+
+// FetchPage はcontextによるキャンセル・期限付きでページを取得します（types.ContextPageFetcher の実装）
+func (f *Fetcher) FetchPage(ctx context.Context, url string) (*types.FetchedPage, error) {
+	res, err := f.FetchContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return &types.FetchedPage{
+		URL:         res.URL,
+		ContentType: res.ContentType,
+		Body:        res.Body,
+		Attempts:    res.Attempts,
+		Truncated:   res.Truncated,
+		Info:        res.FetchInfo(),
+	}, nil
+}
@@ -0,0 +1,118 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+)
+
+func TestFetcher_Fetch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>Hello</body></html>"))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.IgnoreRobotsTxt = true
+	f, err := NewFetcher(cfg)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+	body, err := f.Fetch(ts.URL, 2*time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(body) != "<html><body>Hello</body></html>" {
+		t.Errorf("unexpected body: %s", string(body))
+	}
+	// プロキシ・TLSの指定がない場合はTransportを共有する
+	if f.Options.Client.Transport != defaultTransport {
+		t.Error("expected the shared transport without proxy or TLS settings")
+	}
+}
+
+func TestFetcher_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// プロキシへのリクエストは絶対URLになる
+		proxied = r.URL.String()
+		w.Write([]byte("<html><body>via proxy</body></html>"))
+	}))
+	defer proxy.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.IgnoreRobotsTxt = true
+	cfg.ProxyURL = proxy.URL
+	f, err := NewFetcher(cfg)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+	res, err := f.FetchContext(context.Background(), "http://example.invalid/page")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if proxied != "http://example.invalid/page" || string(res.Body) != "<html><body>via proxy</body></html>" {
+		t.Errorf("expected request via proxy, got '%s'", proxied)
+	}
+
+	cfg.ProxyURL = "not a url"
+	if _, err := NewFetcher(cfg); err == nil {
+		t.Error("expected error for invalid proxy URL")
+	}
+}
+
+func TestFetcher_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>TLS</body></html>"))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.IgnoreRobotsTxt = true
+	cfg.Retry = config.RetryPolicy{}
+
+	// 自己署名証明書は既定では検証に失敗する
+	f, _ := NewFetcher(cfg)
+	if _, err := f.Fetch(ts.URL, 2*time.Second); err == nil {
+		t.Error("expected certificate error without CA certificate")
+	}
+
+	// CA証明書を指定した場合
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	caCfg := cfg
+	caCfg.CACertFile = caFile
+	f, err := NewFetcher(caCfg)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+	if _, err := f.Fetch(ts.URL, 2*time.Second); err != nil {
+		t.Errorf("expected success with CA certificate, got %v", err)
+	}
+
+	// 証明書の検証を省略した場合
+	insecureCfg := cfg
+	insecureCfg.InsecureSkipVerify = true
+	f, err = NewFetcher(insecureCfg)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+	if _, err := f.Fetch(ts.URL, 2*time.Second); err != nil {
+		t.Errorf("expected success with InsecureSkipVerify, got %v", err)
+	}
+
+	caCfg.CACertFile = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := NewFetcher(caCfg); err == nil {
+		t.Error("expected error for missing CA certificate file")
+	}
+}
@@ -0,0 +1,82 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// AcceptEncoding は送信するAccept-Encodingヘッダ（展開は decodeBody で行います）
+const AcceptEncoding = "gzip, deflate, br"
+
+// gzipMagic はgzipデータの先頭2バイト
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decodeBody は Content-Encoding に応じてボディを展開するReaderを返します
+// Content-Encodingの指定がなくてもgzipのマジックナンバーで始まる場合はgzipとして展開します
+func decodeBody(body io.Reader, contentEncoding string) (io.Reader, error) {
+	var encodings []string
+	for _, e := range strings.Split(contentEncoding, ",") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" && e != "identity" {
+			encodings = append(encodings, e)
+		}
+	}
+	if len(encodings) == 0 {
+		// Accept-Encodingを無視して圧縮したまま返すサーバーへの対応
+		br := bufio.NewReader(body)
+		if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+			return gzip.NewReader(br)
+		}
+		return br, nil
+	}
+
+	// 複数指定されている場合は適用された順の逆に展開する
+	r := body
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch encodings[i] {
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(r)
+		case "deflate":
+			r, err = newDeflateReader(r)
+		case "br":
+			r = brotli.NewReader(r)
+		default:
+			return nil, fmt.Errorf("Unsupported content encoding '%s'", encodings[i])
+		}
+		// ボディが空の場合は展開せずに空のボディとして扱う
+		if err == io.EOF {
+			return bytes.NewReader(nil), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode '%s' content: %w", encodings[i], err)
+		}
+	}
+	return r, nil
+}
+
+// newDeflateReader はdeflateのボディを展開するReaderを返します
+// 仕様どおりのzlib形式と、一部のサーバーが送る生のdeflate形式の両方を扱います
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err != nil {
+		// 空のボディは io.EOF をそのまま返し、途中で切れている場合は不正なデータとして扱う
+		if err == io.EOF && len(header) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	// zlibヘッダ: CMF の下位4ビットが8（deflate）かつ CMF*256+FLG が31の倍数
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -0,0 +1,116 @@
+package fetcher
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+const compressedPage = "<html><body>Compressed page</body></html>"
+
+func compress(t *testing.T, encoding string, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	}
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestFetchURLWithOptions_Decompress(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentEncoding string
+		body            []byte
+	}{
+		{"gzip", "gzip", compress(t, "gzip", compressedPage)},
+		{"deflate", "deflate", compress(t, "deflate", compressedPage)},
+		{"raw deflate", "deflate", compress(t, "raw-deflate", compressedPage)},
+		{"brotli", "br", compress(t, "br", compressedPage)},
+		{"gzip then brotli", "gzip, br", compress(t, "br", string(compress(t, "gzip", compressedPage)))},
+		// Content-Encodingを付けずにgzipを返すサーバー
+		{"gzip without header", "", compress(t, "gzip", compressedPage)},
+		{"identity", "identity", []byte(compressedPage)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Accept-Encoding") != AcceptEncoding {
+					t.Errorf("unexpected Accept-Encoding: %s", r.Header.Get("Accept-Encoding"))
+				}
+				w.Header().Set("Content-Type", "text/html")
+				if tt.contentEncoding != "" {
+					w.Header().Set("Content-Encoding", tt.contentEncoding)
+				}
+				w.Write(tt.body)
+			}))
+			defer ts.Close()
+
+			res, err := FetchURLWithOptions(ts.URL, RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if string(res.Body) != compressedPage {
+				t.Errorf("unexpected body: %q", string(res.Body))
+			}
+		})
+	}
+}
+
+func TestFetchURLWithOptions_DecompressLimit(t *testing.T) {
+	// 展開後のサイズに上限が適用されること
+	body := compress(t, "gzip", string(bytes.Repeat([]byte("a"), 1<<20)))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	res, err := FetchURLWithOptions(ts.URL, RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, MaxBodyBytes: 1024})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !res.Truncated || len(res.Body) != 1024 {
+		t.Errorf("expected 1024 decoded bytes, got %d (truncated: %v)", len(res.Body), res.Truncated)
+	}
+}
+
+func TestDecodeBody_UnsupportedEncoding(t *testing.T) {
+	if _, err := decodeBody(bytes.NewReader([]byte("data")), "compress"); err == nil {
+		t.Error("expected error for unsupported content encoding")
+	}
+}
+
+func TestDecodeBody_Empty(t *testing.T) {
+	for _, encoding := range []string{"gzip", "x-gzip", "deflate"} {
+		r, err := decodeBody(bytes.NewReader(nil), encoding)
+		if err != nil {
+			t.Errorf("%s: expected empty body to be accepted, got %v", encoding, err)
+			continue
+		}
+		if body, err := io.ReadAll(r); err != nil || len(body) != 0 {
+			t.Errorf("%s: expected empty body, got %q (%v)", encoding, body, err)
+		}
+	}
+	// 途中で切れたデータはエラー
+	if _, err := decodeBody(bytes.NewReader([]byte{0x78}), "deflate"); err == nil {
+		t.Error("expected error for truncated deflate body")
+	}
+}
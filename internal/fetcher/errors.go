@@ -0,0 +1,59 @@
+package fetcher
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// HTMLContentTypes は解析対象とするHTMLのContent-Type
+var HTMLContentTypes = []string{"text/html", "application/xhtml+xml"}
+
+// ErrHTTPStatus は2xx以外のHTTPステータスが返された場合のエラー（errors.As で判定できます）
+type ErrHTTPStatus struct {
+	URL  string
+	Code int
+}
+
+func (e *ErrHTTPStatus) Error() string {
+	return fmt.Sprintf("Unexpected HTTP status %d from URL '%s'", e.Code, e.URL)
+}
+
+// ErrUnsupportedContentType は解析できないContent-Type（JSON, PDF, 画像など）が返された場合のエラー
+type ErrUnsupportedContentType struct {
+	URL         string
+	ContentType string
+}
+
+func (e *ErrUnsupportedContentType) Error() string {
+	return fmt.Sprintf("Unsupported content type '%s' from URL '%s'", e.ContentType, e.URL)
+}
+
+// ErrBodyTooLarge はレスポンスボディが上限サイズを超えた場合のエラー（FailOnLargeBody 指定時）
+type ErrBodyTooLarge struct {
+	URL   string
+	Limit int64
+}
+
+func (e *ErrBodyTooLarge) Error() string {
+	return fmt.Sprintf("Response body from URL '%s' exceeds %d bytes", e.URL, e.Limit)
+}
+
+// checkContentType は contentType が accepted のいずれかに一致するか確認します
+// accepted が空の場合、およびContent-Typeヘッダがない場合は確認しません
+func checkContentType(url string, contentType string, accepted []string) error {
+	if len(accepted) == 0 || strings.TrimSpace(contentType) == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// パラメータが壊れている場合もメディアタイプ部分で判定する
+		mediaType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	}
+	for _, a := range accepted {
+		if strings.EqualFold(mediaType, a) {
+			return nil
+		}
+	}
+	return &ErrUnsupportedContentType{URL: url, ContentType: contentType}
+}
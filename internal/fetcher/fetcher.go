@@ -1,10 +1,16 @@
 package fetcher
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+	"github.com/xshoji/go-keywordminer/pkg/types"
 )
 
 const UserAgent = "Mozilla/5.0 (compatible; KeywordBot/1.0)"
@@ -12,43 +18,360 @@ const UserAgent = "Mozilla/5.0 (compatible; KeywordBot/1.0)"
 // FetchResult はHTTP取得結果を格納します
 // （今後の拡張用に構造体でラップ）
 type FetchResult struct {
-	URL  string
-	Body []byte
+	URL         string
+	ContentType string
+	Body        []byte
+	// Attempts は再試行を含むリクエストの試行回数
+	Attempts int
+	// Truncated はボディが MaxBodyBytes で切り詰められた場合にtrue
+	Truncated bool
+	// StatusCode は最終レスポンスのステータスコード
+	StatusCode int
+	// Header は最終レスポンスのヘッダ
+	Header http.Header
+	// Redirects は最終URLに至るまでに経由したリダイレクト（古い順）
+	Redirects []types.Redirect
+	// Duration は再試行を含む取得にかかった時間
+	Duration time.Duration
+	// Bytes は受信したボディのバイト数（圧縮されている場合は展開前）
+	Bytes int64
+	// CacheStatus はキャッシュの利用状況（CacheHit, CacheRevalidated, CacheMiss。キャッシュ未使用の場合は空）
+	CacheStatus string
+}
+
+// FetchInfo は解析結果に含めるレスポンス情報を返します
+func (r *FetchResult) FetchInfo() *types.FetchInfo {
+	return &types.FetchInfo{
+		StatusCode:   r.StatusCode,
+		FinalURL:     r.URL,
+		Redirects:    r.Redirects,
+		ContentType:  r.ContentType,
+		LastModified: r.Header.Get("Last-Modified"),
+		ETag:         r.Header.Get("ETag"),
+		XRobotsTag:   strings.Join(r.Header.Values("X-Robots-Tag"), ", "),
+		DurationMs:   r.Duration.Milliseconds(),
+		Bytes:        r.Bytes,
+		Cache:        r.CacheStatus,
+	}
+}
+
+// RequestOptions はHTTPリクエスト時のオプション
+type RequestOptions struct {
+	TimeoutSeconds int
+	// UserAgent が空の場合は UserAgent 定数を使用します
+	UserAgent string
+	// Headers は追加で送信するリクエストヘッダ（Accept-Language, Cookie, Authorization など）
+	Headers map[string]string
+	// Client は複数リクエストで共有するHTTPクライアント（nilの場合はリクエストごとに生成）
+	Client *http.Client
+	// IgnoreRobots がtrueの場合はrobots.txtを確認しません（自サイトの解析用）
+	IgnoreRobots bool
+	// RequestsPerSecond は同一ホストへの1秒あたりの最大リクエスト数（0以下の場合は制限なし）
+	// robots.txtのCrawl-delayの方が長い場合はそちらを優先します
+	RequestsPerSecond float64
+	// HostBurst は同一ホストへ間隔を空けずに送れるリクエスト数（0以下の場合は1）
+	HostBurst int
+	// Robots はrobots.txtのキャッシュ（nilの場合はプロセス内で共有するキャッシュ）
+	Robots *RobotsCache
+	// HostLimiter はホストごとのリクエスト間隔制御（nilの場合はプロセス内で共有するもの）
+	HostLimiter *HostLimiter
+	// Retry は一時的な失敗時の再試行ポリシー（ゼロ値の場合は再試行しない）
+	Retry config.RetryPolicy
+	// ContentTypes は受け付けるContent-Type（空の場合は確認しない）
+	// 一致しない場合は ErrUnsupportedContentType を返します
+	ContentTypes []string
+	// MaxBodyBytes は読み込むボディの最大サイズ（0以下の場合は無制限）
+	MaxBodyBytes int64
+	// FailOnLargeBody がtrueの場合は上限を超えるボディを切り詰めずに ErrBodyTooLarge を返します
+	FailOnLargeBody bool
+	// Cache はレスポンスを保存・再検証するディスクキャッシュ（nilの場合は使用しない）
+	Cache *DiskCache
+}
+
+// NewRequestOptions はConfigからHTTPリクエストのオプションを生成します（HTML以外のContent-Typeはエラー）
+func NewRequestOptions(cfg config.Config) RequestOptions {
+	var cache *DiskCache
+	if cfg.CacheDir != "" {
+		cache = NewDiskCache(cfg.CacheDir, cfg.CacheTTL)
+	}
+	return RequestOptions{
+		TimeoutSeconds:    int(cfg.Timeout.Seconds()),
+		UserAgent:         cfg.UserAgent,
+		Headers:           cfg.Headers,
+		IgnoreRobots:      cfg.IgnoreRobotsTxt,
+		RequestsPerSecond: cfg.RequestsPerSecond,
+		HostBurst:         cfg.HostBurst,
+		Retry:             cfg.Retry,
+		ContentTypes:      HTMLContentTypes,
+		MaxBodyBytes:      cfg.MaxBodyBytes,
+		FailOnLargeBody:   cfg.FailOnLargeBody,
+		Cache:             cache,
+	}
+}
+
+var (
+	// defaultRobotsCache はプロセス内で共有するrobots.txtのキャッシュ
+	defaultRobotsCache = NewRobotsCache()
+	// defaultHostLimiter はプロセス内で共有するホストごとのリクエスト間隔制御
+	defaultHostLimiter = NewHostLimiter()
+)
+
+// NewHTTPClient は接続を使い回すためのHTTPクライアントを生成します
+// 多数のURLを取得する場合はこのクライアントを RequestOptions.Client に指定して共有します
+func NewHTTPClient(timeoutSeconds int) *http.Client {
+	return &http.Client{
+		Timeout:   time.Duration(timeoutSeconds) * time.Second,
+		Transport: newBaseTransport(),
+	}
 }
 
 // FetchURL は指定URLからHTTPレスポンスボディを取得します
 func FetchURL(url string, timeoutSeconds int) (*FetchResult, error) {
-	client := &http.Client{
-		Timeout: time.Duration(timeoutSeconds) * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:       10,
-			IdleConnTimeout:    30 * time.Second,
-			DisableCompression: true,
-		},
+	return FetchURLWithOptions(url, RequestOptions{TimeoutSeconds: timeoutSeconds})
+}
+
+// FetchURLWithOptions はUser-Agentや追加ヘッダを指定してHTTPレスポンスボディを取得します
+func FetchURLWithOptions(url string, opts RequestOptions) (*FetchResult, error) {
+	return FetchURLContext(context.Background(), url, opts)
+}
+
+// FetchURLContext はcontextによるキャンセル・期限付きでHTTPレスポンスボディを取得します
+func FetchURLContext(ctx context.Context, url string, opts RequestOptions) (*FetchResult, error) {
+	client := opts.Client
+	if client == nil {
+		// Transportは共有して接続を使い回す
+		client = &http.Client{
+			Timeout:   time.Duration(opts.TimeoutSeconds) * time.Second,
+			Transport: defaultTransport,
+		}
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create HTTP request for URL '%s': %w", url, err)
 	}
 
-	req.Header.Set("User-Agent", UserAgent)
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = UserAgent
+	}
+
+	// 認証情報を含むレスポンスは共有のキャッシュディレクトリに保存しない
+	cache := opts.Cache
+	if hasCredentialHeaders(opts.Headers) {
+		cache = nil
+	}
+	key := cacheKey(url, userAgent, opts.Headers)
+
+	// 有効期限内のキャッシュがあればリクエストしない
+	var cached *cacheEntry
+	if cache != nil {
+		if cached = cache.load(key); cached != nil && cache.fresh(cached, time.Now()) {
+			if err := checkContentType(cached.FinalURL, cached.ContentType, opts.ContentTypes); err != nil {
+				return nil, err
+			}
+			return cached.result(CacheHit, 0, 0), nil
+		}
+	}
+
+	// robots.txtの確認とホストごとのリクエスト間隔の制御
+	var interval time.Duration
+	if opts.RequestsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / opts.RequestsPerSecond)
+	}
+	limiter := opts.HostLimiter
+	if limiter == nil {
+		limiter = defaultHostLimiter
+	}
+	if !opts.IgnoreRobots {
+		robots := opts.Robots
+		if robots == nil {
+			robots = defaultRobotsCache
+		}
+		rules := robots.Rules(ctx, client, req.URL, userAgent)
+		if !rules.Allowed(robotsPath(req.URL)) {
+			// キャンセル・期限切れでrobots.txtを取得できなかった場合はcontextのエラーを返す
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("Failed to access URL '%s': %w", url, err)
+			}
+			if cause := rules.Unavailable(); cause != nil {
+				// 接続できない場合はページも取得できないため通信エラーとして返す
+				var statusErr *ErrHTTPStatus
+				if !errors.As(cause, &statusErr) {
+					return nil, fmt.Errorf("Failed to access URL '%s': %w", url, cause)
+				}
+				// robots.txtのステータスコードはページの終了コードに影響させないため %v で含める
+				return nil, fmt.Errorf("Failed to access URL '%s': %w (robots.txt unavailable: %v)", url, ErrDisallowedByRobots, cause)
+			}
+			return nil, fmt.Errorf("Failed to access URL '%s': %w", url, ErrDisallowedByRobots)
+		}
+		if rules.CrawlDelay > interval {
+			interval = rules.CrawlDelay
+		}
+	}
 
-	resp, err := client.Do(req)
+	req.Header.Set("User-Agent", userAgent)
+	// 圧縮はTransportに任せず自前で展開する（Accept-Encodingを無視するサーバーにも対応するため）
+	req.Header.Set("Accept-Encoding", AcceptEncoding)
+	for key, value := range opts.Headers {
+		if strings.EqualFold(key, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+	// 期限切れのキャッシュは条件付きGETで再検証する
+	if cached != nil {
+		cached.setValidators(req)
+	}
+
+	// 一時的な失敗（5xx, 429, 接続エラー）は再試行ポリシーに従って再試行する
+	var resp *http.Response
+	attempts := 0
+	start := time.Now()
+	for {
+		attempts++
+		if err := limiter.Wait(ctx, req.URL.Host, interval, opts.HostBurst); err != nil {
+			return nil, fmt.Errorf("Failed to access URL '%s': %w", url, err)
+		}
+		resp, err = client.Do(req.Clone(ctx))
+		wait, retry := retryDelay(ctx, opts.Retry, attempts, resp, err)
+		if !retry {
+			break
+		}
+		if resp != nil {
+			// 接続を再利用できるようボディを読み捨てる
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, fmt.Errorf("Failed to access URL '%s': %w", url, err)
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("Failed to access URL '%s': %w", url, err)
+		return nil, fmt.Errorf("Failed to access URL '%s' (attempts: %d): %w", url, attempts, err)
 	}
+	// 受信したバイト数を数える
+	counter := &countingReadCloser{ReadCloser: resp.Body}
+	resp.Body = counter
 	defer resp.Body.Close()
 
+	// 変更がなければキャッシュを使用する
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		cached.updateValidators(resp.Header)
+		cached.StoredAt = time.Now()
+		if storable(cached.Header) {
+			// キャッシュの書き込みに失敗しても取得結果は返す
+			_ = cache.store(cached)
+		} else {
+			cache.remove(key)
+		}
+		if err := checkContentType(cached.FinalURL, cached.ContentType, opts.ContentTypes); err != nil {
+			return nil, err
+		}
+		return cached.result(CacheRevalidated, attempts, time.Since(start)), nil
+	}
+
 	finalURL := resp.Request.URL.String()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &ErrHTTPStatus{URL: finalURL, Code: resp.StatusCode}
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if err := checkContentType(finalURL, contentType, opts.ContentTypes); err != nil {
+		return nil, err
+	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, truncated, err := readBody(resp, opts.MaxBodyBytes, opts.FailOnLargeBody)
 	if err != nil {
+		var tooLarge *ErrBodyTooLarge
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("Failed to read response body from URL '%s': %w", finalURL, err)
 	}
 
-	return &FetchResult{
-		URL:  finalURL,
-		Body: body,
-	}, nil
+	result := &FetchResult{
+		URL:         finalURL,
+		ContentType: contentType,
+		Body:        body,
+		Attempts:    attempts,
+		Truncated:   truncated,
+		StatusCode:  resp.StatusCode,
+		Header:      resp.Header,
+		Redirects:   redirectChain(resp),
+		Duration:    time.Since(start),
+		Bytes:       counter.n,
+	}
+	if cache != nil {
+		result.CacheStatus = CacheMiss
+		// 切り詰めたボディは上限や FailOnLargeBody の異なる取得で使えないため保存しない
+		if !storable(resp.Header) || truncated {
+			cache.remove(key)
+			return result, nil
+		}
+		// キャッシュの書き込みに失敗しても取得結果は返す
+		_ = cache.store(&cacheEntry{
+			Key:         key,
+			URL:         url,
+			FinalURL:    finalURL,
+			StatusCode:  resp.StatusCode,
+			Header:      resp.Header,
+			Redirects:   result.Redirects,
+			Body:        body,
+			StoredAt:    time.Now(),
+			ContentType: contentType,
+		})
+	}
+	return result, nil
+}
+
+// redirectChain はレスポンスに至るまでに経由したリダイレクトを古い順に返します
+func redirectChain(resp *http.Response) []types.Redirect {
+	var chain []types.Redirect
+	for prev := resp.Request.Response; prev != nil; prev = prev.Request.Response {
+		chain = append([]types.Redirect{{URL: prev.Request.URL.String(), StatusCode: prev.StatusCode}}, chain...)
+	}
+	return chain
+}
+
+// countingReadCloser は読み込んだバイト数を数えるReadCloser
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readBody はレスポンスボディを展開し、最大 maxBytes バイトまで読み込みます
+// 上限を超える場合は切り詰めてtrueを返し、failOnLarge の場合は ErrBodyTooLarge を返します
+// 上限は展開後のサイズに適用されるため、圧縮爆弾でメモリを使い果たすこともありません
+func readBody(resp *http.Response, maxBytes int64, failOnLarge bool) ([]byte, bool, error) {
+	body, err := decodeBody(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, false, err
+	}
+	if maxBytes <= 0 {
+		decoded, err := io.ReadAll(body)
+		return decoded, false, err
+	}
+	// Content-Lengthで上限超過が分かる場合は読み込まずにエラーにする
+	if failOnLarge && resp.ContentLength > maxBytes {
+		return nil, false, &ErrBodyTooLarge{URL: resp.Request.URL.String(), Limit: maxBytes}
+	}
+	// 上限を1バイト超えて読めるかどうかで超過を判定する
+	decoded, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(decoded)) <= maxBytes {
+		return decoded, false, nil
+	}
+	if failOnLarge {
+		return nil, false, &ErrBodyTooLarge{URL: resp.Request.URL.String(), Limit: maxBytes}
+	}
+	return decoded[:maxBytes], true, nil
 }
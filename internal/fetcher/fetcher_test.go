@@ -1,9 +1,13 @@
 package fetcher
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestFetchURL_Success(t *testing.T) {
@@ -36,3 +40,234 @@ func TestFetchURL_Timeout(t *testing.T) {
 		t.Error("expected timeout error, got nil")
 	}
 }
+
+func TestFetchURLWithOptions_Headers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("User-Agent") + "|" + r.Header.Get("Accept-Language") + "|" + r.Header.Get("Authorization")))
+	}))
+	defer ts.Close()
+
+	res, err := FetchURLWithOptions(ts.URL, RequestOptions{
+		TimeoutSeconds: 2,
+		UserAgent:      "TestBot/1.0",
+		Headers:        map[string]string{"Accept-Language": "ja", "Authorization": "Bearer token"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(res.Body) != "TestBot/1.0|ja|Bearer token" {
+		t.Errorf("unexpected headers: %s", string(res.Body))
+	}
+
+	res, err = FetchURL(ts.URL, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(res.Body) != UserAgent+"||" {
+		t.Errorf("expected default User-Agent, got %s", string(res.Body))
+	}
+}
+
+func TestFetchURL_ContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=Shift_JIS")
+		w.Write([]byte("<html></html>"))
+	}))
+	defer ts.Close()
+
+	res, err := FetchURL(ts.URL, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.ContentType != "text/html; charset=Shift_JIS" {
+		t.Errorf("unexpected content type: %s", res.ContentType)
+	}
+}
+
+func TestFetchURLContext_Canceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := FetchURLContext(ctx, ts.URL, RequestOptions{TimeoutSeconds: 10})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestFetchURLWithOptions_Robots(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private"))
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer ts.Close()
+
+	_, err := FetchURLWithOptions(ts.URL+"/private/page", RequestOptions{TimeoutSeconds: 2})
+	if !errors.Is(err, ErrDisallowedByRobots) {
+		t.Errorf("expected ErrDisallowedByRobots, got %v", err)
+	}
+	if _, err := FetchURLWithOptions(ts.URL+"/public", RequestOptions{TimeoutSeconds: 2}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, err := FetchURLWithOptions(ts.URL+"/private/page", RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true}); err != nil {
+		t.Errorf("expected robots.txt to be ignored, got %v", err)
+	}
+}
+
+func TestFetchURLWithOptions_RobotsUnavailable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer ts.Close()
+
+	// robots.txtのステータスコードはページのHTTPステータスエラーとして扱わない
+	_, err := FetchURLWithOptions(ts.URL+"/page", RequestOptions{TimeoutSeconds: 2})
+	var statusErr *ErrHTTPStatus
+	if !errors.Is(err, ErrDisallowedByRobots) || errors.As(err, &statusErr) {
+		t.Errorf("expected ErrDisallowedByRobots without ErrHTTPStatus, got %v", err)
+	}
+
+	// 接続できないホストは通信エラーとして返す
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+	_, err = FetchURLWithOptions(closed.URL+"/page", RequestOptions{TimeoutSeconds: 2})
+	var opErr *net.OpError
+	if errors.Is(err, ErrDisallowedByRobots) || !errors.As(err, &opErr) {
+		t.Errorf("expected a network error, got %v", err)
+	}
+}
+
+func TestFetchURLWithOptions_HTTPStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<html><body>Not Found</body></html>"))
+	}))
+	defer ts.Close()
+
+	_, err := FetchURLWithOptions(ts.URL+"/missing", RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true})
+	var statusErr *ErrHTTPStatus
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected ErrHTTPStatus, got %v", err)
+	}
+	if statusErr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", statusErr.Code)
+	}
+}
+
+func TestFetchURLWithOptions_UnsupportedContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"title":"json"}`))
+		default:
+			w.Header().Set("Content-Type", "application/xhtml+xml; charset=utf-8")
+			w.Write([]byte("<html><body>XHTML</body></html>"))
+		}
+	}))
+	defer ts.Close()
+
+	opts := RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, ContentTypes: HTMLContentTypes}
+	_, err := FetchURLWithOptions(ts.URL+"/data.json", opts)
+	var typeErr *ErrUnsupportedContentType
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("expected ErrUnsupportedContentType, got %v", err)
+	}
+	if typeErr.ContentType != "application/json" {
+		t.Errorf("unexpected content type: %s", typeErr.ContentType)
+	}
+
+	if _, err := FetchURLWithOptions(ts.URL+"/page.xhtml", opts); err != nil {
+		t.Errorf("expected XHTML to be accepted, got %v", err)
+	}
+}
+
+func TestFetchURLWithOptions_MaxBodyBytes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>0123456789</body></html>"))
+	}))
+	defer ts.Close()
+
+	// 上限以下の場合はそのまま
+	res, err := FetchURLWithOptions(ts.URL, RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, MaxBodyBytes: 1024})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Truncated || len(res.Body) != 36 {
+		t.Errorf("expected full body, got %d bytes (truncated: %v)", len(res.Body), res.Truncated)
+	}
+
+	// 上限を超える場合は切り詰める
+	res, err = FetchURLWithOptions(ts.URL, RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, MaxBodyBytes: 16})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !res.Truncated || string(res.Body) != "<html><body>0123" {
+		t.Errorf("expected truncated body, got '%s' (truncated: %v)", string(res.Body), res.Truncated)
+	}
+
+	// FailOnLargeBody の場合はエラー
+	_, err = FetchURLWithOptions(ts.URL, RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true, MaxBodyBytes: 16, FailOnLargeBody: true})
+	var tooLarge *ErrBodyTooLarge
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 16 {
+		t.Errorf("expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestFetchURLWithOptions_ResponseMetadata(t *testing.T) {
+	body := "<html><body>Final page</body></html>"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Add("X-Robots-Tag", "noindex")
+		w.Header().Add("X-Robots-Tag", "nofollow")
+		w.Write([]byte(body))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	res, err := FetchURLWithOptions(ts.URL+"/old", RequestOptions{TimeoutSeconds: 2, IgnoreRobots: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.URL != ts.URL+"/final" || res.StatusCode != http.StatusOK {
+		t.Errorf("unexpected final response: %s (%d)", res.URL, res.StatusCode)
+	}
+	if len(res.Redirects) != 2 ||
+		res.Redirects[0].URL != ts.URL+"/old" || res.Redirects[0].StatusCode != http.StatusMovedPermanently ||
+		res.Redirects[1].URL != ts.URL+"/moved" || res.Redirects[1].StatusCode != http.StatusFound {
+		t.Errorf("unexpected redirect chain: %+v", res.Redirects)
+	}
+	if res.Bytes != int64(len(body)) {
+		t.Errorf("expected %d bytes, got %d", len(body), res.Bytes)
+	}
+	if res.Duration <= 0 {
+		t.Errorf("expected positive duration, got %v", res.Duration)
+	}
+
+	info := res.FetchInfo()
+	if info.ETag != `"v1"` || info.LastModified != "Mon, 01 Jan 2024 00:00:00 GMT" || info.XRobotsTag != "noindex, nofollow" {
+		t.Errorf("unexpected fetch info: %+v", info)
+	}
+	if info.FinalURL != res.URL || info.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("unexpected fetch info: %+v", info)
+	}
+}
@@ -0,0 +1,172 @@
+package fetcher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+)
+
+// ErrFixtureNotFound はリプレイ時に対応するフィクスチャがない場合のエラー
+var ErrFixtureNotFound = errors.New("Fixture not found")
+
+// フィクスチャの動作モード
+const (
+	// FixtureReplay はフィクスチャからレスポンスを返し、ネットワークにはアクセスしません
+	FixtureReplay = "replay"
+	// FixtureRecord は実際に取得したレスポンスをフィクスチャとして保存します
+	FixtureRecord = "record"
+)
+
+// FixtureTransport はレスポンス（ステータス・ヘッダ・ボディ）をフィクスチャとして記録・再生する http.RoundTripper
+// リクエストごと（リダイレクトの各段階、robots.txtを含む）に "<hash>.json"（メタ情報）と "<hash>.body"（ボディ）を保存します
+// ボディは受信したままのバイト列なので、展開・文字コード判定などは通常の取得と同じ処理を通ります
+type FixtureTransport struct {
+	// Dir はフィクスチャを保存するディレクトリ
+	Dir string
+	// Mode は FixtureReplay または FixtureRecord
+	Mode string
+	// Transport は記録時に実際のリクエストに使用するTransport（nilの場合は共有のTransport）
+	Transport http.RoundTripper
+}
+
+// fixtureMeta はフィクスチャのメタ情報
+type fixtureMeta struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+}
+
+// NewFixtureFetcher はフィクスチャを記録・再生するFetcherを生成します
+func NewFixtureFetcher(cfg config.Config, dir string, mode string) (*Fetcher, error) {
+	cfg.FixtureDir = dir
+	cfg.FixtureMode = mode
+	return NewFetcher(cfg)
+}
+
+// useFixtures はFetcherのTransportをフィクスチャの記録・再生に置き換えます
+// 再生時は結果が変わらないよう、リクエスト間隔の制御・再試行・ディスクキャッシュを無効にします
+func (f *Fetcher) useFixtures(dir string, mode string) error {
+	if mode == "" {
+		mode = FixtureReplay
+	}
+	if mode != FixtureReplay && mode != FixtureRecord {
+		return fmt.Errorf("Invalid fixture mode '%s'", mode)
+	}
+	f.Options.Client = &http.Client{
+		Timeout:   f.Options.Client.Timeout,
+		Transport: &FixtureTransport{Dir: dir, Mode: mode, Transport: f.Options.Client.Transport},
+	}
+	f.Options.Cache = nil
+	if mode == FixtureReplay {
+		f.Options.RequestsPerSecond = 0
+		f.Options.Retry = config.RetryPolicy{}
+	}
+	return nil
+}
+
+// fixturePath はリクエストに対応するフィクスチャのパス（拡張子なし）を返します
+func (t *FixtureTransport) fixturePath(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:8]))
+}
+
+// RoundTrip はモードに応じてフィクスチャを再生、または実際のレスポンスを記録します
+func (t *FixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Mode == FixtureRecord {
+		return t.record(req)
+	}
+	return t.replay(req)
+}
+
+// replay はフィクスチャからレスポンスを生成します
+func (t *FixtureTransport) replay(req *http.Request) (*http.Response, error) {
+	path := t.fixturePath(req)
+	data, err := os.ReadFile(path + ".json")
+	if err != nil {
+		// -R で記録した場合などrobots.txtのフィクスチャがない場合は、存在しない（404）として扱う
+		if req.URL.Path == "/robots.txt" {
+			return &http.Response{
+				Status:     "404 Not Found",
+				StatusCode: http.StatusNotFound,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+				Request:    req,
+			}, nil
+		}
+		return nil, fmt.Errorf("%w: %s %s", ErrFixtureNotFound, req.Method, req.URL)
+	}
+	var meta fixtureMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("Failed to parse fixture '%s.json': %w", path, err)
+	}
+	body, err := os.ReadFile(path + ".body")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read fixture '%s.body': %w", path, err)
+	}
+	if meta.Header == nil {
+		meta.Header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", meta.StatusCode, http.StatusText(meta.StatusCode)),
+		StatusCode:    meta.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        meta.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// record は実際にリクエストし、レスポンスをフィクスチャとして保存します
+func (t *FixtureTransport) record(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = defaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read response body from URL '%s': %w", req.URL, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	meta, err := json.MarshalIndent(fixtureMeta{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to encode fixture for URL '%s': %w", req.URL, err)
+	}
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("Failed to create fixture directory '%s': %w", t.Dir, err)
+	}
+	path := t.fixturePath(req)
+	if err := os.WriteFile(path+".body", body, 0o644); err != nil {
+		return nil, fmt.Errorf("Failed to write fixture '%s.body': %w", path, err)
+	}
+	if err := os.WriteFile(path+".json", meta, 0o644); err != nil {
+		return nil, fmt.Errorf("Failed to write fixture '%s.json': %w", path, err)
+	}
+	return resp, nil
+}
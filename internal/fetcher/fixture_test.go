@@ -0,0 +1,129 @@
+package fetcher
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+)
+
+func TestFixtureFetcher_RecordAndReplay(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("ETag", `"fixture"`)
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte("<html><body>Recorded page</body></html>"))
+		zw.Close()
+	})
+	ts := httptest.NewServer(mux)
+	dir := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.IgnoreRobotsTxt = true
+	recorder, err := NewFixtureFetcher(cfg, dir, FixtureRecord)
+	if err != nil {
+		t.Fatalf("NewFixtureFetcher error: %v", err)
+	}
+	recorded, err := recorder.FetchContext(context.Background(), ts.URL+"/old")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// 記録後はサーバーなしで再生できる
+	ts.Close()
+
+	player, err := NewFixtureFetcher(cfg, dir, FixtureReplay)
+	if err != nil {
+		t.Fatalf("NewFixtureFetcher error: %v", err)
+	}
+	replayed, err := player.FetchContext(context.Background(), ts.URL+"/old")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(replayed.Body) != "<html><body>Recorded page</body></html>" || string(replayed.Body) != string(recorded.Body) {
+		t.Errorf("unexpected replayed body: %s", string(replayed.Body))
+	}
+	if replayed.URL != ts.URL+"/page" || len(replayed.Redirects) != 1 || replayed.Redirects[0].StatusCode != http.StatusMovedPermanently {
+		t.Errorf("unexpected replayed redirects: %s %+v", replayed.URL, replayed.Redirects)
+	}
+	if replayed.Header.Get("ETag") != `"fixture"` || replayed.StatusCode != http.StatusOK {
+		t.Errorf("unexpected replayed response: %d %v", replayed.StatusCode, replayed.Header)
+	}
+
+	if _, err := player.FetchContext(context.Background(), ts.URL+"/unknown"); !errors.Is(err, ErrFixtureNotFound) {
+		t.Errorf("expected ErrFixtureNotFound, got %v", err)
+	}
+	if _, err := NewFixtureFetcher(cfg, dir, "live"); err == nil {
+		t.Error("expected error for invalid fixture mode")
+	}
+}
+
+func TestFixtureFetcher_Robots(t *testing.T) {
+	var blocked int32 = 1
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&blocked) == 1 {
+			w.Write([]byte("User-agent: *\nDisallow: /\n"))
+		}
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body>Live page</body></html>"))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// 記録・再生したrobots.txtは他のFetcherに影響しない
+	cfg := config.DefaultConfig()
+	dir := t.TempDir()
+	recorder, err := NewFixtureFetcher(cfg, dir, FixtureRecord)
+	if err != nil {
+		t.Fatalf("NewFixtureFetcher error: %v", err)
+	}
+	if _, err := recorder.FetchContext(context.Background(), ts.URL+"/page"); !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots, got %v", err)
+	}
+	player, err := NewFixtureFetcher(cfg, dir, FixtureReplay)
+	if err != nil {
+		t.Fatalf("NewFixtureFetcher error: %v", err)
+	}
+	if _, err := player.FetchContext(context.Background(), ts.URL+"/page"); !errors.Is(err, ErrDisallowedByRobots) {
+		t.Errorf("expected replayed robots.txt to disallow, got %v", err)
+	}
+	atomic.StoreInt32(&blocked, 0)
+	live, err := NewFetcher(cfg)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+	if _, err := live.FetchContext(context.Background(), ts.URL+"/page"); err != nil {
+		t.Errorf("expected live fetch to use its own robots.txt, got %v", err)
+	}
+
+	// robots.txtを記録していない場合は再生時に404（すべて許可）として扱う
+	cfg.IgnoreRobotsTxt = true
+	dir = t.TempDir()
+	recorder, err = NewFixtureFetcher(cfg, dir, FixtureRecord)
+	if err != nil {
+		t.Fatalf("NewFixtureFetcher error: %v", err)
+	}
+	if _, err := recorder.FetchContext(context.Background(), ts.URL+"/page"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	cfg.IgnoreRobotsTxt = false
+	player, err = NewFixtureFetcher(cfg, dir, FixtureReplay)
+	if err != nil {
+		t.Fatalf("NewFixtureFetcher error: %v", err)
+	}
+	if res, err := player.FetchContext(context.Background(), ts.URL+"/page"); err != nil || string(res.Body) != "<html><body>Live page</body></html>" {
+		t.Errorf("expected missing robots.txt fixture to allow, got %v", err)
+	}
+}
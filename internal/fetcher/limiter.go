@@ -0,0 +1,69 @@
+package fetcher
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// HostLimiter はホストごとのトークンバケットでリクエスト間隔を制御します
+type HostLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewHostLimiter は空のHostLimiterを生成します
+func NewHostLimiter() *HostLimiter {
+	return &HostLimiter{buckets: make(map[string]*tokenBucket)}
+}
+
+// Wait は host へのリクエストが許可されるまで待機します
+// interval ごとに1トークン補充され、最大 burst 回までは連続してリクエストできます
+// （interval が0以下の場合は待機しません）
+func (l *HostLimiter) Wait(ctx context.Context, host string, interval time.Duration, burst int) error {
+	if interval <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		l.buckets[host] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+float64(now.Sub(b.last))/float64(interval))
+	b.last = now
+	// 先にトークンを予約し、不足分の時間だけ待機する
+	b.tokens--
+	wait := time.Duration(0)
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens * float64(interval))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// 使わなかった予約を返却
+		l.mu.Lock()
+		b.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
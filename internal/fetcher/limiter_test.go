@@ -0,0 +1,41 @@
+package fetcher
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter_Wait(t *testing.T) {
+	l := NewHostLimiter()
+	interval := 50 * time.Millisecond
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background(), "example.com", interval, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// 2回はバースト、3回目は1間隔分待つ
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected to wait about %v, waited %v", interval, elapsed)
+	}
+
+	// 他のホストは影響を受けない
+	start = time.Now()
+	if err := l.Wait(context.Background(), "other.example", interval, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("expected no wait for another host, waited %v", elapsed)
+	}
+}
+
+func TestHostLimiter_Canceled(t *testing.T) {
+	l := NewHostLimiter()
+	_ = l.Wait(context.Background(), "example.com", time.Hour, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "example.com", time.Hour, 1); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package fetcher
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+)
+
+// isRetryableStatus は再試行すべき一時的なエラーのステータスコードか判定します
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay は attempt 回目の試行結果から再試行するかどうかと、それまでの待機時間を返します
+// 接続エラー（ctxのキャンセル・期限切れを除く）と isRetryableStatus のステータスを再試行します
+func retryDelay(ctx context.Context, policy config.RetryPolicy, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= policy.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+	if err != nil {
+		return backoff(policy, attempt), true
+	}
+	if !isRetryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+	wait := backoff(policy, attempt)
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		// 待機時間の上限を超える指定の場合は待たずに諦める
+		if policy.MaxBackoff > 0 && retryAfter > policy.MaxBackoff {
+			return 0, false
+		}
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+	}
+	return wait, true
+}
+
+// backoff は attempt 回目の失敗後の待機時間（指数バックオフ＋ジッター）を返します
+func backoff(policy config.RetryPolicy, attempt int) time.Duration {
+	wait := policy.InitialBackoff
+	for i := 1; i < attempt && (policy.MaxBackoff <= 0 || wait < policy.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+		wait = policy.MaxBackoff
+	}
+	if policy.Jitter > 0 {
+		wait += time.Duration(float64(wait) * policy.Jitter * (2*rand.Float64() - 1))
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// parseRetryAfter はRetry-Afterヘッダ（秒数またはHTTP日付）を待機時間に変換します
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := at.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+// sleepContext は d だけ待機します（ctxがキャンセルされた場合はそのエラーを返します）
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,134 @@
+package fetcher
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xshoji/go-keywordminer/pkg/config"
+)
+
+func TestFetchURLWithOptions_Retry(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if atomic.AddInt32(&count, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	res, err := FetchURLWithOptions(ts.URL, RequestOptions{
+		TimeoutSeconds: 2,
+		Retry:          config.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(res.Body) != "ok" {
+		t.Errorf("unexpected body: %s", string(res.Body))
+	}
+	if res.Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", res.Attempts)
+	}
+}
+
+func TestFetchURLWithOptions_RetryAfterTooLong(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	_, err := FetchURLWithOptions(ts.URL, RequestOptions{
+		TimeoutSeconds: 2,
+		Retry:          config.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: time.Second},
+	})
+	var statusErr *ErrHTTPStatus
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected ErrHTTPStatus 429, got %v", err)
+	}
+	// 上限を超えるRetry-Afterは待たずに諦める
+	if n := atomic.LoadInt32(&count); n != 1 {
+		t.Errorf("expected no retry, got %d attempts", n)
+	}
+}
+
+func TestFetchURLWithOptions_NoRetryOnClientError(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&count, 1)
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	_, err := FetchURLWithOptions(ts.URL+"/missing", RequestOptions{
+		TimeoutSeconds: 2,
+		Retry:          config.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond},
+	})
+	var statusErr *ErrHTTPStatus
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Fatalf("expected ErrHTTPStatus 404, got %v", err)
+	}
+	if n := atomic.LoadInt32(&count); n != 1 {
+		t.Errorf("expected no retry for 404, got %d attempts", n)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"0", 0, true},
+		{"Mon, 01 Jan 2024 00:00:30 GMT", 30 * time.Second, true},
+		{"Sun, 31 Dec 2023 00:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := config.RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := backoff(policy, i+1); got != w {
+			t.Errorf("backoff(attempt=%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		if got := backoff(policy, 1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Errorf("backoff with jitter out of range: %v", got)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisallowedByRobots はrobots.txtで取得が禁止されているURLを取得しようとした場合のエラー
+var ErrDisallowedByRobots = errors.New("Disallowed by robots.txt")
+
+// maxRobotsBytes は読み込むrobots.txtの最大サイズ（Googleの上限に合わせて500KiB）
+const maxRobotsBytes = 500 * 1024
+
+// RobotsRules は特定のUser-Agentに適用されるrobots.txtのルール
+type RobotsRules struct {
+	rules      []robotsRule
+	CrawlDelay time.Duration
+	// unavailable はrobots.txtを取得できずにすべて禁止として扱っている場合の原因
+	unavailable error
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	// re は pattern を変換した正規表現（ParseRobots で一度だけコンパイルする）
+	re *regexp.Regexp
+}
+
+// newRobotsRule はパターンをコンパイルしてルールを生成します
+func newRobotsRule(allow bool, pattern string) robotsRule {
+	return robotsRule{allow: allow, pattern: pattern, re: compileRobotsPattern(pattern)}
+}
+
+// robotsGroup はrobots.txtのUser-agentグループ
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// ParseRobots はrobots.txtを解析し、userAgent に適用されるルールを返します
+// userAgent のプロダクトトークン（"Mozilla/5.0 (compatible; KeywordBot/1.0)" なら "keywordbot"）と
+// 大文字小文字を区別せずに一致するグループを使い、なければ "*" のグループを使います（同名のグループは結合します）
+func ParseRobots(body []byte, userAgent string) *RobotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// 連続するUser-agent行は同じグループ
+			if current == nil || !lastWasAgent {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			// 空のDisallowは「すべて許可」なのでルールとしては扱わない
+			if current != nil && value != "" {
+				current.rules = append(current.rules, newRobotsRule(key == "allow", value))
+			}
+		case "crawl-delay":
+			if current != nil {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					current.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+		lastWasAgent = false
+	}
+
+	token := robotsProductToken(userAgent)
+	selected := selectRobotsGroups(groups, func(agent string) bool { return token != "" && strings.EqualFold(agent, token) })
+	if len(selected) == 0 {
+		selected = selectRobotsGroups(groups, func(agent string) bool { return agent == "*" })
+	}
+	result := &RobotsRules{}
+	for _, g := range selected {
+		result.rules = append(result.rules, g.rules...)
+		if g.crawlDelay > result.CrawlDelay {
+			result.CrawlDelay = g.crawlDelay
+		}
+	}
+	return result
+}
+
+// selectRobotsGroups は match に一致するUser-agentを含むグループを返します
+func selectRobotsGroups(groups []*robotsGroup, match func(agent string) bool) []*robotsGroup {
+	var selected []*robotsGroup
+	for _, g := range groups {
+		for _, a := range g.agents {
+			if match(a) {
+				selected = append(selected, g)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// robotsProductToken はUser-Agentからrobots.txtと照合するプロダクトトークンを小文字で返します
+// "名前/バージョン" 形式のうち "Mozilla" 以外の最初の名前を使い、なければ先頭の名前を使います
+func robotsProductToken(userAgent string) string {
+	fields := strings.FieldsFunc(userAgent, func(r rune) bool {
+		return r == ' ' || r == ';' || r == '(' || r == ')'
+	})
+	var names []string
+	for _, f := range fields {
+		if name, _, found := strings.Cut(f, "/"); found && name != "" {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if !strings.EqualFold(name, "mozilla") {
+			return strings.ToLower(name)
+		}
+	}
+	if len(names) > 0 {
+		return strings.ToLower(names[0])
+	}
+	if len(fields) > 0 {
+		return strings.ToLower(fields[0])
+	}
+	return ""
+}
+
+// disallowAllRules はすべてのパスを禁止するルール（robots.txtを取得できない場合に使用）
+func disallowAllRules(cause error) *RobotsRules {
+	return &RobotsRules{rules: []robotsRule{newRobotsRule(false, "/")}, unavailable: cause}
+}
+
+// Unavailable はrobots.txtを取得できずにすべて禁止として扱っている場合にその原因を返します
+func (r *RobotsRules) Unavailable() error {
+	return r.unavailable
+}
+
+// Allowed はパス（クエリ文字列を含む）の取得が許可されているか判定します
+// 一致するルールのうち最も長いパターンを優先し、同じ長さの場合はAllowを優先します
+func (r *RobotsRules) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	allowed := true
+	matchedLength := -1
+	for _, rule := range r.rules {
+		if rule.re == nil || !rule.re.MatchString(path) {
+			continue
+		}
+		if len(rule.pattern) > matchedLength || (len(rule.pattern) == matchedLength && rule.allow) {
+			allowed = rule.allow
+			matchedLength = len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+// compileRobotsPattern はrobots.txtのパターン（"*" はワイルドカード、末尾の "$" は終端）を
+// パスと照合する正規表現に変換します（変換できない場合はnil）
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// robotsRetryInterval はrobots.txtの取得に失敗した場合に再取得するまでの間隔（その間はすべて禁止として扱う）
+var robotsRetryInterval = time.Minute
+
+// RobotsCache はホストごとのrobots.txtを取得・保持します
+type RobotsCache struct {
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	mu      sync.Mutex
+	fetched bool
+	body    []byte
+	// err・failedAt は直近の取得の失敗とその時刻（取得に成功するまで robotsRetryInterval ごとに再取得する）
+	err      error
+	failedAt time.Time
+}
+
+// NewRobotsCache は空のRobotsCacheを生成します
+func NewRobotsCache() *RobotsCache {
+	return &RobotsCache{entries: make(map[string]*robotsEntry)}
+}
+
+// Rules は u のホストのrobots.txtを（未取得なら取得して）userAgent 向けのルールを返します
+// RFC 9309 に従い、robots.txtが存在しない（4xx）場合はすべて許可、
+// サーバーエラー（5xx, 429）や接続できない場合はすべて禁止として扱います
+func (c *RobotsCache) Rules(ctx context.Context, client *http.Client, u *url.URL, userAgent string) *RobotsRules {
+	key := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &robotsEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if !entry.fetched && (entry.err == nil || time.Since(entry.failedAt) >= robotsRetryInterval) {
+		body, err := fetchRobots(ctx, client, key+"/robots.txt", userAgent)
+		switch {
+		case err == nil:
+			entry.body, entry.fetched, entry.err = body, true, nil
+		case ctx.Err() != nil:
+			// キャンセルで取得できなかった場合は記録せず次回に再取得する
+			return disallowAllRules(err)
+		default:
+			entry.err, entry.failedAt = err, time.Now()
+		}
+	}
+	if !entry.fetched {
+		return disallowAllRules(entry.err)
+	}
+	return ParseRobots(entry.body, userAgent)
+}
+
+// fetchRobots はrobots.txtの内容を取得します
+// 4xxの場合は nil（すべて許可）、5xx・429・接続エラー・読み込みエラーの場合はエラーを返します
+func fetchRobots(ctx context.Context, client *http.Client, robotsURL string, userAgent string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request for '%s': %w", robotsURL, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept-Encoding", AcceptEncoding)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch '%s': %w", robotsURL, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, fmt.Errorf("Failed to fetch '%s': %w", robotsURL, &ErrHTTPStatus{URL: robotsURL, Code: resp.StatusCode})
+	case resp.StatusCode >= 400:
+		return nil, nil
+	}
+	decoded, err := decodeBody(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode '%s': %w", robotsURL, err)
+	}
+	body, err := io.ReadAll(io.LimitReader(decoded, maxRobotsBytes))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read '%s': %w", robotsURL, err)
+	}
+	return body, nil
+}
+
+// robotsPath はrobots.txtのルールと照合するパス（クエリ文字列を含む）を返します
+func robotsPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
@@ -0,0 +1,155 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRobots = `# comment
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: OtherBot
+User-agent: KeywordBot
+Disallow: /keywordbot-only
+Crawl-delay: 0.5
+`
+
+func TestParseRobots_GroupSelection(t *testing.T) {
+	rules := ParseRobots([]byte(testRobots), UserAgent)
+	if rules.Allowed("/keywordbot-only/page") {
+		t.Error("expected /keywordbot-only to be disallowed for KeywordBot")
+	}
+	if !rules.Allowed("/private/page") {
+		t.Error("expected the '*' group not to apply to KeywordBot")
+	}
+	if rules.CrawlDelay != 500*time.Millisecond {
+		t.Errorf("expected crawl delay 500ms, got %v", rules.CrawlDelay)
+	}
+}
+
+func TestParseRobots_Wildcard(t *testing.T) {
+	rules := ParseRobots([]byte(testRobots), "SomeBot/1.0")
+	cases := map[string]bool{
+		"/":                    true,
+		"/private/page":        false,
+		"/private/public/page": true,
+		"/docs/file.pdf":       false,
+		"/docs/file.pdf?x=1":   true,
+	}
+	for path, expected := range cases {
+		if rules.Allowed(path) != expected {
+			t.Errorf("Allowed(%s): expected %v", path, expected)
+		}
+	}
+	if rules.CrawlDelay != 2*time.Second {
+		t.Errorf("expected crawl delay 2s, got %v", rules.CrawlDelay)
+	}
+}
+
+func TestParseRobots_Empty(t *testing.T) {
+	rules := ParseRobots(nil, UserAgent)
+	if !rules.Allowed("/anything") {
+		t.Error("expected everything to be allowed without robots.txt")
+	}
+}
+
+func TestRobotsCache_FetchesOncePerHost(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&count, 1)
+			w.Write([]byte("User-agent: *\nDisallow: /admin"))
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer ts.Close()
+
+	cache := NewRobotsCache()
+	u, _ := url.Parse(ts.URL + "/admin/page")
+	for i := 0; i < 3; i++ {
+		if cache.Rules(context.Background(), ts.Client(), u, UserAgent).Allowed(robotsPath(u)) {
+			t.Error("expected /admin to be disallowed")
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected robots.txt to be fetched once, got %d", count)
+	}
+}
+
+func TestParseRobots_ProductToken(t *testing.T) {
+	// "mozilla" や "bot" は User-Agent 文字列に含まれるが、プロダクトトークン（keywordbot）ではない
+	body := "User-agent: mozilla\nDisallow: /\n\nUser-agent: b\nDisallow: /\n\nUser-agent: *\nDisallow: /private/\n\nUser-agent: KEYWORDBOT\nDisallow: /drafts/\n"
+	rules := ParseRobots([]byte(body), UserAgent)
+	if !rules.Allowed("/articles/") || !rules.Allowed("/private/") {
+		t.Error("expected decoy groups and the '*' group not to apply to KeywordBot")
+	}
+	if rules.Allowed("/drafts/1") {
+		t.Error("expected the KeywordBot group to apply")
+	}
+	if token := robotsProductToken(UserAgent); token != "keywordbot" {
+		t.Errorf("expected product token 'keywordbot', got '%s'", token)
+	}
+
+	// KeywordBot のグループがなければ "*" のグループを使う
+	rules = ParseRobots([]byte("User-agent: mozilla\nDisallow: /\n\nUser-agent: *\nDisallow: /private/\n"), UserAgent)
+	if !rules.Allowed("/articles/") || rules.Allowed("/private/") {
+		t.Error("expected the '*' group to apply")
+	}
+}
+
+func TestRobotsCache_FetchFailures(t *testing.T) {
+	var status int32 = http.StatusNotFound
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL + "/page")
+
+	// 404 はすべて許可
+	if rules := NewRobotsCache().Rules(context.Background(), ts.Client(), u, UserAgent); !rules.Allowed(robotsPath(u)) || rules.Unavailable() != nil {
+		t.Error("expected 404 robots.txt to allow everything")
+	}
+
+	// 503 はすべて禁止し、再取得の間隔内はリクエストしない
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	cache := NewRobotsCache()
+	for i := 0; i < 2; i++ {
+		rules := cache.Rules(context.Background(), ts.Client(), u, UserAgent)
+		if rules.Allowed(robotsPath(u)) || rules.Unavailable() == nil {
+			t.Error("expected 503 robots.txt to disallow everything")
+		}
+	}
+	if n := atomic.LoadInt32(&count); n != 2 {
+		t.Errorf("expected one request per cache, got %d", n)
+	}
+
+	// 失敗は robotsRetryInterval の間だけ記録されるため、間隔を過ぎると再取得して回復する
+	defer func(interval time.Duration) { robotsRetryInterval = interval }(robotsRetryInterval)
+	robotsRetryInterval = 0
+	atomic.StoreInt32(&status, http.StatusNotFound)
+	if !cache.Rules(context.Background(), ts.Client(), u, UserAgent).Allowed(robotsPath(u)) {
+		t.Error("expected robots.txt to be refetched after a failure")
+	}
+}
+
+func TestRobotsCache_ConnectionRefused(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+	u, _ := url.Parse(ts.URL + "/page")
+	rules := NewRobotsCache().Rules(context.Background(), http.DefaultClient, u, UserAgent)
+	if rules.Allowed(robotsPath(u)) || rules.Unavailable() == nil {
+		t.Error("expected unreachable robots.txt to disallow everything")
+	}
+}
@@ -1,6 +1,8 @@
 package japanese
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"unicode"
 
@@ -8,16 +10,36 @@ import (
 	"github.com/ikawaha/kagome/v2/tokenizer"
 )
 
+// ctxCheckInterval はトークン走査中にcontextのキャンセルを確認する間隔
+const ctxCheckInterval = 1000
+
 // ExtractJapaneseKeywords 日本語テキストからキーワードを抽出
 func ExtractJapaneseKeywords(text string) []string {
-	t, err := tokenizer.New(ipa.Dict(), tokenizer.OmitBosEos())
+	keywords, err := ExtractJapaneseKeywordsContext(context.Background(), text)
 	if err != nil {
 		return []string{}
 	}
+	return keywords
+}
+
+// ExtractJapaneseKeywordsContext はcontextのキャンセルを考慮して日本語テキストからキーワードを抽出します
+func ExtractJapaneseKeywordsContext(ctx context.Context, text string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	t, err := tokenizer.New(ipa.Dict(), tokenizer.OmitBosEos())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create Japanese tokenizer: %w", err)
+	}
 	tokens := t.Tokenize(text)
 	keywordMap := make(map[string]bool)
 	normalizedMap := make(map[string]string)
-	for _, token := range tokens {
+	for i, token := range tokens {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		features := token.Features()
 		if len(features) == 0 || features[0] != "名詞" {
 			continue
@@ -48,7 +70,7 @@ func ExtractJapaneseKeywords(text string) []string {
 			result = append(result, norm)
 		}
 	}
-	return result
+	return result, nil
 }
 
 // isSymbolOrPunctuation 日本語用: 記号や特殊文字のみか判定
@@ -1,6 +1,8 @@
 package japanese
 
 import (
+	"context"
+	"errors"
 	"testing"
 )
 
@@ -35,3 +37,12 @@ func TestIsSymbolOrPunctuation(t *testing.T) {
 		t.Error("expected false for empty string")
 	}
 }
+
+func TestExtractJapaneseKeywordsContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ExtractJapaneseKeywordsContext(ctx, "これはテスト用の文章です。")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
+)
+
+// FetchImageAlts は画像の alt 属性の値を返します
+func (h *HTMLDocument) FetchImageAlts() []string {
+	return selectionValues(h.Doc.Find("img[alt]"), func(s *goquery.Selection) string {
+		return s.AttrOr("alt", "")
+	})
+}
+
+// FetchFigcaptions は <figcaption> のテキストを返します
+func (h *HTMLDocument) FetchFigcaptions() []string {
+	return selectionValues(h.Doc.Find("figcaption"), func(s *goquery.Selection) string {
+		return s.Text()
+	})
+}
+
+// FetchTitleAttributes はbody内の要素の title 属性の値を返します
+func (h *HTMLDocument) FetchTitleAttributes() []string {
+	return selectionValues(h.Doc.Find("body [title]"), func(s *goquery.Selection) string {
+		return s.AttrOr("title", "")
+	})
+}
+
+// FetchAnchorTexts はリンクのアンカーテキストを返します
+// ナビゲーション・ヘッダ・フッタ・サイドバーのリンクはページ共通のメニューであることが多いため除外します
+func (h *HTMLDocument) FetchAnchorTexts() []string {
+	links := h.Doc.Find("a[href]").Not("nav a, header a, footer a, aside a")
+	return selectionValues(links, func(s *goquery.Selection) string {
+		return s.Text()
+	})
+}
+
+// selectionValues は各要素から取り出した値（空白を正規化し、空の値は除く）を返します
+func selectionValues(sel *goquery.Selection, value func(*goquery.Selection) string) []string {
+	var result []string
+	sel.Each(func(i int, s *goquery.Selection) {
+		if v := utils.NormalizeSpace(value(s)); v != "" {
+			result = append(result, v)
+		}
+	})
+	return result
+}
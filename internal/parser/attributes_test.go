@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFetchAttributeSources(t *testing.T) {
+	html := `<html><head><link rel="alternate" title="RSS feed" href="/feed.xml"></head><body>
+	<nav><a href="/">Home</a></nav>
+	<figure><img src="/pasta.jpg" alt="Fresh  tagliatelle"><figcaption>Hand-rolled <b>pasta</b></figcaption></figure>
+	<img src="/spacer.gif" alt="">
+	<p>See the <a href="/sauce" title="Tomato sauce recipe">sauce</a>.</p>
+	<footer><a href="/privacy">Privacy</a></footer>
+	</body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alts := doc.FetchImageAlts(); !reflect.DeepEqual(alts, []string{"Fresh tagliatelle"}) {
+		t.Errorf("unexpected alts: %v", alts)
+	}
+	if captions := doc.FetchFigcaptions(); !reflect.DeepEqual(captions, []string{"Hand-rolled pasta"}) {
+		t.Errorf("unexpected figcaptions: %v", captions)
+	}
+	if titles := doc.FetchTitleAttributes(); !reflect.DeepEqual(titles, []string{"Tomato sauce recipe"}) {
+		t.Errorf("unexpected title attributes: %v", titles)
+	}
+	if anchors := doc.FetchAnchorTexts(); !reflect.DeepEqual(anchors, []string{"sauce"}) {
+		t.Errorf("unexpected anchor texts: %v", anchors)
+	}
+}
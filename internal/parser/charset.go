@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// DecodeHTML はHTMLのバイト列をUTF-8文字列に変換します
+// エンコーディングは BOM → Content-Type の charset → <meta charset>/http-equiv → UTF-8妥当性 の順で判定し、
+// 判定したエンコーディング名（例: "utf-8", "shift_jis", "euc-jp", "windows-1252"）を併せて返します
+func DecodeHTML(body []byte, contentType string) (string, string, error) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	// 判定材料がない場合の既定値は windows-1252 だが、先頭1024バイト以降に
+	// UTF-8の文字があるページも多いため、全体がUTF-8として妥当ならUTF-8とみなす
+	if !certain && name == "windows-1252" && utf8.Valid(body) {
+		name = "utf-8"
+	}
+	if name == "utf-8" {
+		// BOMはgoqueryのパースに影響しないが、文字列としては不要なので除去
+		return string(trimUTF8BOM(body)), name, nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return "", name, fmt.Errorf("Failed to decode HTML as '%s': %w", name, err)
+	}
+	return string(decoded), name, nil
+}
+
+// trimUTF8BOM は先頭のUTF-8 BOMを取り除きます
+func trimUTF8BOM(body []byte) []byte {
+	if len(body) >= 3 && body[0] == 0xEF && body[1] == 0xBB && body[2] == 0xBF {
+		return body[3:]
+	}
+	return body
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestDecodeHTML_ContentType(t *testing.T) {
+	body, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte("<html><body><h1>日本語のページ</h1></body></html>"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html, name, err := DecodeHTML(body, "text/html; charset=Shift_JIS")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "shift_jis" {
+		t.Errorf("expected shift_jis, got %s", name)
+	}
+	if !strings.Contains(html, "日本語のページ") {
+		t.Errorf("unexpected decoded html: %s", html)
+	}
+}
+
+func TestDecodeHTML_MetaCharset(t *testing.T) {
+	for _, head := range []string{
+		`<meta charset="EUC-JP">`,
+		`<meta http-equiv="Content-Type" content="text/html; charset=euc-jp">`,
+	} {
+		body, err := japanese.EUCJP.NewEncoder().Bytes([]byte("<html><head>" + head + "</head><body>形態素解析</body></html>"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		html, name, err := DecodeHTML(body, "text/html")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if name != "euc-jp" {
+			t.Errorf("expected euc-jp, got %s", name)
+		}
+		if !strings.Contains(html, "形態素解析") {
+			t.Errorf("unexpected decoded html: %s", html)
+		}
+	}
+}
+
+func TestDecodeHTML_BOMAndDefault(t *testing.T) {
+	html, name, err := DecodeHTML(append([]byte{0xEF, 0xBB, 0xBF}, []byte("<p>テスト</p>")...), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "utf-8" || html != "<p>テスト</p>" {
+		t.Errorf("unexpected result: %s, %q", name, html)
+	}
+
+	html, name, err = DecodeHTML([]byte("<p>caf\xe9</p>"), "text/html; charset=iso-8859-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "windows-1252" || html != "<p>café</p>" {
+		t.Errorf("unexpected result: %s, %q", name, html)
+	}
+}
+
+func TestDecodeHTML_UndeclaredUTF8(t *testing.T) {
+	body := "<html><head><title>" + strings.Repeat("a", 1100) + "</title></head><body>日本語</body></html>"
+	html, name, err := DecodeHTML([]byte(body), "text/html")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "utf-8" || html != body {
+		t.Errorf("expected undeclared UTF-8 to be kept as is, got %s", name)
+	}
+}
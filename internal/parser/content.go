@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
 )
 
 // HTMLDocument は goquery.Document のラッパー
@@ -31,3 +32,37 @@ func (h *HTMLDocument) FetchTags(tag string) []string {
 	})
 	return result
 }
+
+// FetchLinks は a[href] のリンク先をすべて抜き出します（相対URLはそのまま返します）
+func (h *HTMLDocument) FetchLinks() []string {
+	var result []string
+	h.Doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		if href := strings.TrimSpace(s.AttrOr("href", "")); href != "" {
+			result = append(result, href)
+		}
+	})
+	return result
+}
+
+// FetchSelectionText はセレクタに一致する要素のテキスト（script・styleなどの定型要素を除く）を返します
+func (h *HTMLDocument) FetchSelectionText(selector string) string {
+	var texts []string
+	h.Doc.Find(selector).Each(func(i int, s *goquery.Selection) {
+		for _, n := range s.Nodes {
+			collectText(n, false, &texts)
+		}
+	})
+	return utils.NormalizeSpace(strings.Join(texts, " "))
+}
+
+// RemoveSelection はセレクタに一致する要素を文書から取り除きます
+func (h *HTMLDocument) RemoveSelection(selector string) {
+	h.Doc.Find(selector).Remove()
+}
+
+// WithoutSelection はセレクタに一致する要素を取り除いた文書の複製を返します（元の文書は変更しません）
+func (h *HTMLDocument) WithoutSelection(selector string) *HTMLDocument {
+	clone := &HTMLDocument{Doc: goquery.CloneDocument(h.Doc)}
+	clone.RemoveSelection(selector)
+	return clone
+}
@@ -26,3 +26,43 @@ func TestParseHTMLDocument_InvalidHTML(t *testing.T) {
 		t.Error("goquery should not error on empty string")
 	}
 }
+
+func TestFetchLinks(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<html><body><a href="/a">A</a><a>no href</a><a href=" https://example.com/b ">B</a><a href="">empty</a></body></html>`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	links := doc.FetchLinks()
+	if len(links) != 2 || links[0] != "/a" || links[1] != "https://example.com/b" {
+		t.Errorf("unexpected links: %v", links)
+	}
+}
+
+func TestFetchSelectionTextAndRemoveSelection(t *testing.T) {
+	html := `<html><body><div class="article-body"><p>Sourdough starter</p><script>track()</script></div>
+	<div class="recommend">Related bread recipes</div></body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := doc.FetchSelectionText(".article-body"); text != "Sourdough starter" {
+		t.Errorf("unexpected selection text: '%s'", text)
+	}
+	doc.RemoveSelection(".recommend")
+	if text := doc.FetchSelectionText("body"); text != "Sourdough starter" {
+		t.Errorf("expected removed element to be excluded, got '%s'", text)
+	}
+}
+
+func TestWithoutSelection(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<html><body><h1>Title</h1><p>Body text</p></body></html>`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := doc.WithoutSelection("h1").FetchSelectionText("body"); text != "Body text" {
+		t.Errorf("expected heading to be removed from the copy, got '%s'", text)
+	}
+	if text := doc.FetchSelectionText("body"); text != "Title Body text" {
+		t.Errorf("expected original document to be unchanged, got '%s'", text)
+	}
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/config"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+)
+
+// BoilerplateParser は定型要素（nav/header/footer等）の除去で本文を抽出する DocumentParser 実装
+type BoilerplateParser struct{}
+
+// ReadabilityParser はコンテンツ密度スコアで本文ブロックを選ぶ DocumentParser 実装
+type ReadabilityParser struct{}
+
+var (
+	_ types.DocumentParser = BoilerplateParser{}
+	_ types.DocumentParser = ReadabilityParser{}
+)
+
+// NewDocumentParser は config.ContentExtractor* の指定に応じた DocumentParser を返します
+// 未知の指定の場合は BoilerplateParser を返します
+func NewDocumentParser(extractor string) types.DocumentParser {
+	switch extractor {
+	case config.ContentExtractorReadability:
+		return ReadabilityParser{}
+	default:
+		return BoilerplateParser{}
+	}
+}
+
+func (BoilerplateParser) ParseTitle(doc *goquery.Document) (string, error) {
+	return parseTitle(doc), nil
+}
+
+func (BoilerplateParser) ParseMetaTags(doc *goquery.Document) (map[string]string, error) {
+	return (&HTMLDocument{Doc: doc}).FetchMetaTags(), nil
+}
+
+func (BoilerplateParser) ParseMainContent(doc *goquery.Document) (string, error) {
+	return (&HTMLDocument{Doc: doc}).FetchMainContent(), nil
+}
+
+func (ReadabilityParser) ParseTitle(doc *goquery.Document) (string, error) {
+	return parseTitle(doc), nil
+}
+
+func (ReadabilityParser) ParseMetaTags(doc *goquery.Document) (map[string]string, error) {
+	return (&HTMLDocument{Doc: doc}).FetchMetaTags(), nil
+}
+
+func (ReadabilityParser) ParseMainContent(doc *goquery.Document) (string, error) {
+	return (&HTMLDocument{Doc: doc}).ExtractReadableContent(), nil
+}
+
+// parseTitle は最初のtitleタグの内容を返します
+func parseTitle(doc *goquery.Document) string {
+	titles := (&HTMLDocument{Doc: doc}).FetchTags("title")
+	if len(titles) == 0 {
+		return ""
+	}
+	return titles[0]
+}
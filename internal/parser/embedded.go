@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
+	"golang.org/x/net/html"
+)
+
+// MinVisibleContentRunes は表示される本文がこの文字数未満の場合に「ほぼ空」とみなす閾値
+const MinVisibleContentRunes = 200
+
+// minEmbeddedTextRunes は埋め込みデータの文字列を本文として扱う最小の文字数
+const minEmbeddedTextRunes = 4
+
+// embeddedSkipKeys は埋め込みデータのうち本文ではない（ID・URL・設定など）値のキー
+var embeddedSkipKeys = map[string]bool{
+	"buildid": true, "__typename": true, "id": true, "_id": true, "uuid": true, "key": true,
+	"slug": true, "url": true, "href": true, "src": true, "srcset": true, "image": true, "images": true,
+	"thumbnail": true, "icon": true, "locale": true, "locales": true, "defaultlocale": true,
+	"assetprefix": true, "runtimeconfig": true, "query": true, "page": true, "scriptloader": true,
+	"classname": true, "class": true, "style": true, "css": true, "type": true, "@type": true,
+	"@context": true, "@id": true, "datepublished": true, "datemodified": true, "createdat": true,
+	"updatedat": true, "mimetype": true, "path": true, "route": true,
+}
+
+// jsStringLiteralPattern はJavaScriptのダブルクォート文字列リテラル
+var jsStringLiteralPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// IsNearlyEmpty は表示される本文がほとんどない（JavaScriptで描画するSPAの空のシェルなど）か判定します
+func IsNearlyEmpty(visibleText string) bool {
+	return utf8.RuneCountInString(strings.TrimSpace(visibleText)) < MinVisibleContentRunes
+}
+
+// FetchEmbeddedContent はJavaScriptで描画されるページのためにサーバー側で埋め込まれたデータから本文テキストを抽出します
+// 対象は __NEXT_DATA__ / __NUXT_DATA__ のJSON、window.__NUXT__ の文字列、<noscript> の内容、JSON-LD の articleBody です
+func (h *HTMLDocument) FetchEmbeddedContent() string {
+	var texts []string
+
+	// Next.js / Nuxt 3 のJSON
+	h.Doc.Find("script#__NEXT_DATA__, script#__NUXT_DATA__").Each(func(i int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err == nil {
+			collectEmbeddedStrings(data, &texts)
+		}
+	})
+
+	// Nuxt 2 の window.__NUXT__（関数呼び出し形式の場合もあるため文字列リテラルを取り出す）
+	h.Doc.Find("script").Each(func(i int, s *goquery.Selection) {
+		script := s.Text()
+		if !strings.Contains(script, "window.__NUXT__") {
+			return
+		}
+		for _, literal := range jsStringLiteralPattern.FindAllString(script, -1) {
+			if value, err := strconv.Unquote(literal); err == nil {
+				appendEmbeddedText(value, &texts)
+			}
+		}
+	})
+
+	// <noscript> はパース時にテキストとして扱われるため、HTMLとして再解析する
+	h.Doc.Find("noscript").Each(func(i int, s *goquery.Selection) {
+		text := htmlToText(s.Text())
+		// 「JavaScriptを有効にしてください」といった案内文は除外
+		if utf8.RuneCountInString(text) < MinVisibleContentRunes && strings.Contains(strings.ToLower(text), "javascript") {
+			return
+		}
+		if text != "" {
+			texts = append(texts, text)
+		}
+	})
+
+	// JSON-LD の articleBody
+	h.Doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err == nil {
+			for _, body := range findJSONValues(data, "articleBody") {
+				appendEmbeddedText(body, &texts)
+			}
+		}
+	})
+
+	return utils.NormalizeSpace(strings.Join(utils.UniqueStrings(texts), " "))
+}
+
+// collectEmbeddedStrings はJSONの値から本文らしい文字列を収集します（キー順に辿るため結果は決定的）
+func collectEmbeddedStrings(v interface{}, texts *[]string) {
+	switch value := v.(type) {
+	case string:
+		appendEmbeddedText(value, texts)
+	case []interface{}:
+		for _, item := range value {
+			collectEmbeddedStrings(item, texts)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			if !embeddedSkipKeys[strings.ToLower(k)] {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectEmbeddedStrings(value[k], texts)
+		}
+	}
+}
+
+// appendEmbeddedText は本文らしい文字列であれば（HTMLの場合はテキストにして）追加します
+func appendEmbeddedText(s string, texts *[]string) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "<") && strings.Contains(s, ">") {
+		s = htmlToText(s)
+	}
+	if isProseText(s) {
+		*texts = append(*texts, s)
+	}
+}
+
+// isProseText はURL・ID・パスなどではなく文章らしい文字列か判定します
+// 英語などは空白を含むもの、日本語などは非ASCII文字を含むものを文章とみなします
+func isProseText(s string) bool {
+	if utf8.RuneCountInString(s) < minEmbeddedTextRunes {
+		return false
+	}
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "/") || strings.HasPrefix(s, "#") {
+		return false
+	}
+	if strings.ContainsRune(s, ' ') {
+		return true
+	}
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlToText はHTML断片から定型要素を除いたテキストを返します
+func htmlToText(fragment string) string {
+	doc, err := html.Parse(strings.NewReader(fragment))
+	if err != nil {
+		return ""
+	}
+	var texts []string
+	collectText(doc, false, &texts)
+	return utils.NormalizeSpace(strings.Join(texts, " "))
+}
+
+// findJSONValues はJSON（@graphなどの入れ子を含む）から指定キーの文字列値をすべて返します
+func findJSONValues(v interface{}, key string) []string {
+	var result []string
+	switch value := v.(type) {
+	case []interface{}:
+		for _, item := range value {
+			result = append(result, findJSONValues(item, key)...)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s, ok := value[k].(string); ok && k == key {
+				result = append(result, s)
+				continue
+			}
+			result = append(result, findJSONValues(value[k], key)...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFetchEmbeddedContent_NextData(t *testing.T) {
+	html := `<html><body><div id="__next"></div>
+	<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"post":{
+		"id":"p-123","slug":"rust-ownership","title":"Understanding Rust ownership",
+		"body":"<p>Ownership rules <b>borrowing</b> and lifetimes.</p>",
+		"cover":"https://cdn.example.com/cover.png","tags":["rust","memory safety"]}}},
+		"page":"/posts/[slug]","buildId":"abc123"}</script>
+	</body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content := doc.FetchEmbeddedContent()
+	for _, want := range []string{"Understanding Rust ownership", "Ownership rules borrowing and lifetimes.", "memory safety"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected '%s' in embedded content, got '%s'", want, content)
+		}
+	}
+	for _, unwanted := range []string{"p-123", "rust-ownership", "cdn.example.com", "abc123", "<p>"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("unexpected '%s' in embedded content: '%s'", unwanted, content)
+		}
+	}
+}
+
+func TestFetchEmbeddedContent_NuxtNoscriptJSONLD(t *testing.T) {
+	html := `<html><head>
+	<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+		{"@type":"WebSite","name":"Example"},
+		{"@type":"Article","headline":"h","articleBody":"Kubernetes schedules containers across nodes."}]}</script>
+	</head><body><div id="__nuxt"></div>
+	<script>window.__NUXT__=(function(a){return {data:[{title:"Vue server rendering guide",id:a}]}}("x1"))</script>
+	<noscript>You need to enable JavaScript to run this app.</noscript>
+	<noscript><p>Static fallback describing terraform modules.</p></noscript>
+	</body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content := doc.FetchEmbeddedContent()
+	for _, want := range []string{"Kubernetes schedules containers across nodes.", "Vue server rendering guide", "Static fallback describing terraform modules."} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected '%s' in embedded content, got '%s'", want, content)
+		}
+	}
+	if strings.Contains(content, "enable JavaScript") {
+		t.Errorf("expected JavaScript notice to be skipped, got '%s'", content)
+	}
+}
+
+func TestIsNearlyEmpty(t *testing.T) {
+	if !IsNearlyEmpty("Loading...") {
+		t.Error("expected short text to be nearly empty")
+	}
+	if IsNearlyEmpty(strings.Repeat("content ", 50)) {
+		t.Error("expected long text not to be nearly empty")
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+)
+
+// FetchBaseHref は <base href> の値を返します（指定がない場合は空文字）
+func (h *HTMLDocument) FetchBaseHref() string {
+	return strings.TrimSpace(h.Doc.Find("base[href]").First().AttrOr("href", ""))
+}
+
+// FetchLinkRel は rel 属性に指定した値（"canonical" など）を含む <link> の href を返します（相対URLはそのまま返します）
+func (h *HTMLDocument) FetchLinkRel(rel string) []string {
+	var result []string
+	h.Doc.Find("link[rel][href]").Each(func(i int, s *goquery.Selection) {
+		if !hasRel(s, rel) {
+			return
+		}
+		if href := strings.TrimSpace(s.AttrOr("href", "")); href != "" {
+			result = append(result, href)
+		}
+	})
+	return result
+}
+
+// FetchHreflangLinks は hreflang を指定した <link rel="alternate"> を返します（相対URLはそのまま返します）
+func (h *HTMLDocument) FetchHreflangLinks() []types.Alternate {
+	var result []types.Alternate
+	h.Doc.Find("link[rel][hreflang][href]").Each(func(i int, s *goquery.Selection) {
+		if !hasRel(s, "alternate") {
+			return
+		}
+		hreflang := strings.TrimSpace(s.AttrOr("hreflang", ""))
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+		if hreflang != "" && href != "" {
+			result = append(result, types.Alternate{Hreflang: hreflang, URL: href})
+		}
+	})
+	return result
+}
+
+// hasRel は rel 属性（空白区切り・大文字小文字を区別しない）に指定した値が含まれるか判定します
+func hasRel(s *goquery.Selection, rel string) bool {
+	for _, r := range strings.Fields(s.AttrOr("rel", "")) {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package parser
+
+import "testing"
+
+func TestFetchLinkRel(t *testing.T) {
+	html := `<html><head>
+	<base href="https://cdn.example.com/en/">
+	<link rel="Canonical" href=" /articles/go ">
+	<link rel="amphtml" href="/amp/articles/go">
+	<link rel="alternate" hreflang="ja" href="https://example.com/ja/articles/go">
+	<link rel="alternate" hreflang="x-default" href="/articles/go">
+	<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+	<link rel="stylesheet" href="/style.css">
+	</head><body></body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if base := doc.FetchBaseHref(); base != "https://cdn.example.com/en/" {
+		t.Errorf("unexpected base href: '%s'", base)
+	}
+	if canonical := doc.FetchLinkRel("canonical"); len(canonical) != 1 || canonical[0] != "/articles/go" {
+		t.Errorf("unexpected canonical: %+v", canonical)
+	}
+	if amp := doc.FetchLinkRel("amphtml"); len(amp) != 1 || amp[0] != "/amp/articles/go" {
+		t.Errorf("unexpected amphtml: %+v", amp)
+	}
+	alternates := doc.FetchHreflangLinks()
+	if len(alternates) != 2 || alternates[0].Hreflang != "ja" || alternates[1].Hreflang != "x-default" || alternates[1].URL != "/articles/go" {
+		t.Errorf("unexpected alternates: %+v", alternates)
+	}
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
+	"golang.org/x/net/html"
+)
+
+// boilerplateTags は本文抽出時に常に除外する要素
+var boilerplateTags = map[string]bool{
+	"nav": true, "aside": true, "script": true, "style": true, "noscript": true,
+	"template": true, "iframe": true, "form": true, "svg": true, "button": true,
+	"select": true, "textarea": true,
+}
+
+// pageChromeTags はページ全体の共通部品として除外する要素
+// （article内のheaderは記事見出しを含むことが多いため、body起点の場合のみ除外）
+var pageChromeTags = map[string]bool{
+	"header": true, "footer": true,
+}
+
+// FetchMainContent はbody内の本文テキストを抽出します
+// article/main要素があればその中を優先し、なければbody全体から定型要素を除いたテキストを返します
+func (h *HTMLDocument) FetchMainContent() string {
+	root := h.Doc.Find("article").Not("article article")
+	skipChrome := false
+	if root.Length() == 0 {
+		root = h.Doc.Find("main, [role=main]").First()
+	}
+	if root.Length() == 0 {
+		root = h.Doc.Find("body")
+		skipChrome = true
+	}
+
+	var texts []string
+	root.Each(func(i int, s *goquery.Selection) {
+		for _, n := range s.Nodes {
+			collectText(n, skipChrome, &texts)
+		}
+	})
+	return utils.NormalizeSpace(strings.Join(texts, " "))
+}
+
+// collectText はノード配下のテキストを除外要素をスキップしながら収集します
+func collectText(n *html.Node, skipChrome bool, texts *[]string) {
+	switch n.Type {
+	case html.TextNode:
+		if text := strings.TrimSpace(n.Data); text != "" {
+			*texts = append(*texts, text)
+		}
+		return
+	case html.ElementNode:
+		if boilerplateTags[n.Data] || (skipChrome && pageChromeTags[n.Data]) {
+			return
+		}
+	case html.CommentNode:
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		collectText(c, skipChrome, texts)
+	}
+}
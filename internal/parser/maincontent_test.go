@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFetchMainContent_Body(t *testing.T) {
+	html := `<html><head><title>t</title><style>.a{}</style></head><body>
+	<header>Site Header</header>
+	<nav><ul><li>Home</li><li>About</li></ul></nav>
+	<h1>Golang</h1>
+	<p>Concurrency is easy.</p><p>Channels are great.</p>
+	<ul><li>goroutine</li></ul>
+	<aside>Related articles</aside>
+	<script>var x = 1;</script>
+	<footer>Copyright</footer>
+	</body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content := doc.FetchMainContent()
+	expected := "Golang Concurrency is easy. Channels are great. goroutine"
+	if content != expected {
+		t.Errorf("expected '%s', got '%s'", expected, content)
+	}
+}
+
+func TestFetchMainContent_Article(t *testing.T) {
+	html := `<html><body>
+	<header>Site Header</header>
+	<div class="menu">Menu</div>
+	<article><header><h1>Title</h1></header><p>Article body.</p></article>
+	</body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content := doc.FetchMainContent()
+	if content != "Title Article body." {
+		t.Errorf("expected 'Title Article body.', got '%s'", content)
+	}
+	if strings.Contains(content, "Menu") {
+		t.Errorf("content outside article should be ignored: %s", content)
+	}
+}
@@ -6,36 +6,77 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
+// metaNameTargets は name 属性で抽出するmetaタグ
+var metaNameTargets = map[string]bool{
+	"description": true, "pubdate": true, "keywords": true, "author": true,
+	"robots": true, "news_keywords": true,
+}
+
+// metaPrefixTargets は name / property 属性の接頭辞で抽出するmetaタグ
+// （twitter:* は name、og:* と article:* は property で指定されることが多いが、どちらも受け付ける）
+var metaPrefixTargets = []string{"og:", "twitter:", "article:", "citation_"}
+
+// multiValueMetaKeys は複数指定されることがあり、"; " で連結して保持するmetaタグ
+// （citation_author は "姓, 名" の形式のため "," では区切らない）
+var multiValueMetaKeys = map[string]bool{
+	"article:tag": true, "article:author": true, "citation_author": true,
+	"citation_keywords": true, "og:image": true, "og:locale:alternate": true,
+}
+
+// metaValueSeparator は複数指定されたmetaタグの値を連結する区切り文字
+const metaValueSeparator = "; "
+
 // FetchMetaTags はmetaタグから指定属性の値を抽出します
+// description・keywords などに加え、og:*・twitter:*・article:*・citation_* のすべてのタグを返します
 func (h *HTMLDocument) FetchMetaTags() map[string]string {
 	result := make(map[string]string)
 
-	metaNameTargets := []string{"description", "pubdate", "keywords"}
-	metaPropTargets := []string{"og:description", "og:site_name"}
-
 	h.Doc.Find("meta").Each(func(i int, s *goquery.Selection) {
-		if name, exists := s.Attr("name"); exists {
-			name = strings.ToLower(name)
-			for _, target := range metaNameTargets {
-				if name == target {
-					if content, ok := s.Attr("content"); ok {
-						result[name] = content
-					}
-					break
-				}
-			}
+		content, ok := s.Attr("content")
+		if !ok {
+			return
 		}
-		if prop, exists := s.Attr("property"); exists {
-			prop = strings.ToLower(prop)
-			for _, target := range metaPropTargets {
-				if prop == target {
-					if content, ok := s.Attr("content"); ok {
-						result[prop] = content
-					}
-					break
+		content = strings.TrimSpace(content)
+		for _, attr := range []string{"name", "property"} {
+			key, exists := s.Attr(attr)
+			if !exists {
+				continue
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			if !isMetaTarget(key) {
+				continue
+			}
+			if existing := result[key]; multiValueMetaKeys[key] && existing != "" && content != "" {
+				if !containsMetaValue(existing, content) {
+					result[key] = existing + metaValueSeparator + content
 				}
+				continue
 			}
+			result[key] = content
 		}
 	})
 	return result
 }
+
+// isMetaTarget は抽出対象のmetaタグか判定します
+func isMetaTarget(key string) bool {
+	if metaNameTargets[key] {
+		return true
+	}
+	for _, prefix := range metaPrefixTargets {
+		if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsMetaValue は連結済みの値に value が既に含まれるか判定します
+func containsMetaValue(joined, value string) bool {
+	for _, v := range strings.Split(joined, metaValueSeparator) {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -33,3 +33,52 @@ func TestFetchMetaTags(t *testing.T) {
 		t.Errorf("expected sitename, got %s", meta["og:site_name"])
 	}
 }
+
+func TestFetchMetaTags_Extended(t *testing.T) {
+	html := `<html><head>
+	<meta property="og:title" content="OG Title">
+	<meta property="og:type" content="article">
+	<meta name="twitter:card" content="summary_large_image">
+	<meta name="twitter:description" content="twitter desc">
+	<meta property="article:section" content="Technology">
+	<meta property="article:tag" content="golang">
+	<meta property="article:tag" content="concurrency">
+	<meta property="article:tag" content="golang">
+	<meta name="author" content="Jane Doe">
+	<meta name="robots" content="index, follow">
+	<meta name="news_keywords" content="go, channels">
+	<meta name="citation_title" content="A Study of Goroutines">
+	<meta name="citation_author" content="Doe, Jane">
+	<meta name="citation_author" content="Roe, Richard">
+	<meta name="viewport" content="width=device-width">
+	<meta property="og:" content="empty suffix">
+	</head></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	meta := doc.FetchMetaTags()
+	expected := map[string]string{
+		"og:title":            "OG Title",
+		"og:type":             "article",
+		"twitter:card":        "summary_large_image",
+		"twitter:description": "twitter desc",
+		"article:section":     "Technology",
+		"article:tag":         "golang; concurrency",
+		"author":              "Jane Doe",
+		"robots":              "index, follow",
+		"news_keywords":       "go, channels",
+		"citation_title":      "A Study of Goroutines",
+		"citation_author":     "Doe, Jane; Roe, Richard",
+	}
+	for key, want := range expected {
+		if meta[key] != want {
+			t.Errorf("expected %s='%s', got '%s'", key, want, meta[key])
+		}
+	}
+	for _, key := range []string{"viewport", "og:"} {
+		if _, ok := meta[key]; ok {
+			t.Errorf("unexpected meta tag '%s'", key)
+		}
+	}
+}
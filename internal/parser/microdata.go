@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
+)
+
+// fetchMicrodata は itemscope / itemprop 属性のMicrodataを抽出します
+// 他のアイテムのプロパティになっているアイテム（brand など）は親のプロパティの値として扱います
+func (h *HTMLDocument) fetchMicrodata() []types.StructuredData {
+	var result []types.StructuredData
+	h.Doc.Find("[itemscope]:not([itemprop])").Each(func(i int, s *goquery.Selection) {
+		props := map[string][]string{}
+		collectItemProperties(s, "itemscope", "itemprop", props)
+		if item, ok := structuredItem(FormatMicrodata, itemTypeNames(s.AttrOr("itemtype", "")), props); ok {
+			result = append(result, item)
+		}
+	})
+	return result
+}
+
+// fetchRDFa は typeof / property 属性のRDFa（RDFa Lite）を抽出します
+func (h *HTMLDocument) fetchRDFa() []types.StructuredData {
+	var result []types.StructuredData
+	h.Doc.Find("[typeof]:not([property])").Each(func(i int, s *goquery.Selection) {
+		props := map[string][]string{}
+		collectItemProperties(s, "typeof", "property", props)
+		if item, ok := structuredItem(FormatRDFa, itemTypeNames(s.AttrOr("typeof", "")), props); ok {
+			result = append(result, item)
+		}
+	})
+	return result
+}
+
+// collectItemProperties はアイテムに直接属するプロパティの値を収集します
+// 入れ子のアイテム（scopeAttr を持つ要素）の内部はそのアイテムのプロパティのため辿りません
+func collectItemProperties(s *goquery.Selection, scopeAttr string, propAttr string, props map[string][]string) {
+	s.Children().Each(func(i int, c *goquery.Selection) {
+		_, nested := c.Attr(scopeAttr)
+		if names, ok := c.Attr(propAttr); ok {
+			value := itemPropertyValue(c)
+			if nested {
+				value = nestedItemName(c, scopeAttr, propAttr)
+			}
+			for _, name := range strings.Fields(names) {
+				if value != "" {
+					name = localName(name)
+					props[name] = append(props[name], value)
+				}
+			}
+		}
+		if !nested {
+			collectItemProperties(c, scopeAttr, propAttr, props)
+		}
+	})
+}
+
+// nestedItemName は入れ子のアイテム（Brand・Thing など）の name、なければテキストを返します
+func nestedItemName(s *goquery.Selection, scopeAttr string, propAttr string) string {
+	props := map[string][]string{}
+	collectItemProperties(s, scopeAttr, propAttr, props)
+	if names := props["name"]; len(names) > 0 {
+		return names[0]
+	}
+	return utils.NormalizeSpace(s.Text())
+}
+
+// itemPropertyValue は要素の種類に応じたプロパティの値を返します
+func itemPropertyValue(s *goquery.Selection) string {
+	if content, ok := s.Attr("content"); ok {
+		return utils.NormalizeSpace(content)
+	}
+	attr := ""
+	switch goquery.NodeName(s) {
+	case "a", "area", "link":
+		attr = "href"
+	case "img", "audio", "video", "source", "embed", "iframe":
+		attr = "src"
+	case "object":
+		attr = "data"
+	case "data", "meter":
+		attr = "value"
+	case "time":
+		attr = "datetime"
+	}
+	if value, ok := s.Attr(attr); attr != "" && ok {
+		return strings.TrimSpace(value)
+	}
+	return utils.NormalizeSpace(s.Text())
+}
+
+// itemTypeNames は itemtype / typeof の値（"https://schema.org/Product" や "schema:Product"）から型名を返します
+func itemTypeNames(value string) []string {
+	var names []string
+	for _, t := range strings.Fields(value) {
+		names = append(names, localName(t))
+	}
+	return names
+}
+
+// localName はURLや接頭辞付きの名前（"schema:name" など）から末尾の名前を返します
+func localName(name string) string {
+	return name[strings.LastIndexAny(name, "/#:")+1:]
+}
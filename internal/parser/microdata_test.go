@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFetchStructuredData_Microdata(t *testing.T) {
+	html := `<html><body>
+	<div itemscope itemtype="https://schema.org/Product">
+		<h1 itemprop="name">Trail Running Shoes</h1>
+		<div itemprop="brand" itemscope itemtype="https://schema.org/Brand"><meta itemprop="name" content="Acme"></div>
+		<span itemprop="category">Footwear &gt; Running</span>
+		<img itemprop="image" src="/shoes.png" alt="shoes">
+		<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+			<span itemprop="name">Summer sale</span><meta itemprop="price" content="99.00">
+		</div>
+	</div>
+	<div itemscope itemtype="https://schema.org/BreadcrumbList"><a itemprop="url" href="/shoes">Shoes</a></div>
+	</body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := doc.FetchStructuredData()
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %+v", items)
+	}
+	product := items[0]
+	if product.Format != FormatMicrodata || product.Type != "Product" || product.Name != "Trail Running Shoes" || product.Brand != "Acme" {
+		t.Errorf("unexpected product: %+v", product)
+	}
+	if !reflect.DeepEqual(product.Category, []string{"Footwear > Running"}) {
+		t.Errorf("unexpected category: %+v", product.Category)
+	}
+}
+
+func TestFetchStructuredData_RDFa(t *testing.T) {
+	html := `<html><head><meta property="og:title" content="Not an RDFa item"></head><body>
+	<div vocab="https://schema.org/" typeof="Product">
+		<span property="name">Espresso Machine</span>
+		<div property="brand" typeof="Brand"><span property="name">Barista Co</span></div>
+		<meta property="schema:category" content="Kitchen">
+		<span property="keywords">coffee, espresso</span>
+	</div>
+	</body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := doc.FetchStructuredData()
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %+v", items)
+	}
+	product := items[0]
+	if product.Format != FormatRDFa || product.Type != "Product" || product.Name != "Espresso Machine" || product.Brand != "Barista Co" {
+		t.Errorf("unexpected product: %+v", product)
+	}
+	if !reflect.DeepEqual(product.Category, []string{"Kitchen"}) || !reflect.DeepEqual(product.Keywords, []string{"coffee", "espresso"}) {
+		t.Errorf("unexpected category/keywords: %+v %+v", product.Category, product.Keywords)
+	}
+}
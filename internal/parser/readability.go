@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
+	"golang.org/x/net/html"
+)
+
+var (
+	// unlikelyCandidatesPattern は本文である可能性が低いclass/idのパターン
+	unlikelyCandidatesPattern = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|disqus|extra|footer|header|legends|menu|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|ad-break|agegate|pagination|pager|popup|recommend|widget`)
+	// maybeCandidatePattern は unlikelyCandidatesPattern に該当しても本文の可能性があるパターン
+	maybeCandidatePattern = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	// positiveHintPattern は本文らしさを加点するclass/idのパターン
+	positiveHintPattern = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	// negativeHintPattern は本文らしさを減点するclass/idのパターン
+	negativeHintPattern = regexp.MustCompile(`(?i)hidden|banner|combx|comment|com-|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget|recommend`)
+)
+
+// minParagraphLength はスコア対象とする段落の最小文字数
+const minParagraphLength = 25
+
+// ExtractReadableContent はコンテンツ密度スコアにより本文ブロックを選び、そのテキストを返します
+// （テキスト量・カンマ数・リンク密度・class/idのヒントを元にReadability風に採点）
+// 候補が見つからない場合は FetchMainContent の結果を返します
+func (h *HTMLDocument) ExtractReadableContent() string {
+	top := h.findTopCandidate()
+	if top == nil {
+		return h.FetchMainContent()
+	}
+	var texts []string
+	for c := top.FirstChild; c != nil; c = c.NextSibling {
+		collectReadableText(c, &texts)
+	}
+	return utils.NormalizeSpace(strings.Join(texts, " "))
+}
+
+// findTopCandidate は最もスコアの高い本文候補ノードを返します
+func (h *HTMLDocument) findTopCandidate() *html.Node {
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+
+	h.Doc.Find("p, pre, td, blockquote").Each(func(i int, s *goquery.Selection) {
+		node := s.Nodes[0]
+		if hasUnlikelyAncestor(node) {
+			return
+		}
+		text := utils.NormalizeSpace(s.Text())
+		if len([]rune(text)) < minParagraphLength {
+			return
+		}
+
+		// 基本点 + カンマ（読点）の数 + 100文字ごとに1点（最大3点）
+		contentScore := 1.0
+		contentScore += float64(strings.Count(text, ",") + strings.Count(text, "、"))
+		contentScore += math.Min(float64(len([]rune(text)))/100, 3)
+
+		parent := node.Parent
+		for level := 0; parent != nil && parent.Type == html.ElementNode && level < 2; level++ {
+			if _, ok := scores[parent]; !ok {
+				scores[parent] = initialNodeScore(parent)
+				candidates = append(candidates, parent)
+			}
+			if level == 0 {
+				scores[parent] += contentScore
+			} else {
+				scores[parent] += contentScore / 2
+			}
+			parent = parent.Parent
+		}
+	})
+
+	var top *html.Node
+	topScore := 0.0
+	for _, c := range candidates {
+		score := scores[c] * (1 - linkDensity(c))
+		if top == nil || score > topScore {
+			top = c
+			topScore = score
+		}
+	}
+	return top
+}
+
+// initialNodeScore はタグ名とclass/idから候補ノードの初期スコアを算出します
+func initialNodeScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.Data {
+	case "article":
+		score += 10
+	case "div", "main", "section":
+		score += 5
+	case "pre", "td", "blockquote":
+		score += 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score -= 3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score -= 5
+	case "body":
+		score -= 5
+	}
+	return score + classWeight(n)
+}
+
+// classWeight はclass/id属性のヒントから加減点を算出します
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, attr := range []string{"class", "id"} {
+		value := nodeAttr(n, attr)
+		if value == "" {
+			continue
+		}
+		if negativeHintPattern.MatchString(value) {
+			weight -= 25
+		}
+		if positiveHintPattern.MatchString(value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity はノード内テキストに占めるリンクテキストの割合を返します
+func linkDensity(n *html.Node) float64 {
+	sel := goquery.NewDocumentFromNode(n).Selection
+	textLength := len([]rune(utils.NormalizeSpace(sel.Text())))
+	if textLength == 0 {
+		return 0
+	}
+	linkLength := 0
+	sel.Find("a").Each(func(i int, s *goquery.Selection) {
+		linkLength += len([]rune(utils.NormalizeSpace(s.Text())))
+	})
+	return float64(linkLength) / float64(textLength)
+}
+
+// isUnlikelyCandidate はclass/idから本文でない可能性が高い要素か判定します
+func isUnlikelyCandidate(n *html.Node) bool {
+	if n.Type != html.ElementNode || n.Data == "body" || n.Data == "article" || n.Data == "a" {
+		return false
+	}
+	hint := nodeAttr(n, "class") + " " + nodeAttr(n, "id")
+	return unlikelyCandidatesPattern.MatchString(hint) && !maybeCandidatePattern.MatchString(hint)
+}
+
+// hasUnlikelyAncestor は自身または祖先に本文でない可能性が高い要素があるか判定します
+func hasUnlikelyAncestor(n *html.Node) bool {
+	for p := n; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && (boilerplateTags[p.Data] || pageChromeTags[p.Data] || isUnlikelyCandidate(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectReadableText は本文候補ノード配下のテキストを、定型要素や低スコア要素を除いて収集します
+func collectReadableText(n *html.Node, texts *[]string) {
+	switch n.Type {
+	case html.TextNode:
+		if text := strings.TrimSpace(n.Data); text != "" {
+			*texts = append(*texts, text)
+		}
+		return
+	case html.ElementNode:
+		if boilerplateTags[n.Data] || isUnlikelyCandidate(n) || isLowQualityBlock(n) {
+			return
+		}
+	case html.CommentNode:
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		collectReadableText(c, texts)
+	}
+}
+
+// isLowQualityBlock は本文候補内のブロックのうち、減点ヒントを持つものやリンク主体のものを判定します
+// （「関連記事」などのリンク集を本文から除くため）
+func isLowQualityBlock(n *html.Node) bool {
+	switch n.Data {
+	case "div", "section", "ul", "ol", "table", "dl":
+	default:
+		return false
+	}
+	return classWeight(n) < 0 || linkDensity(n) > 0.5
+}
+
+// nodeAttr はノードの属性値を返します（存在しない場合は空文字）
+func nodeAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const newsPageHTML = `<html><head><title>News</title></head><body>
+<div id="menu"><a href="/">Home</a> <a href="/world">World</a> <a href="/sports">Sports</a></div>
+<div class="content">
+  <h1>Volcano erupts</h1>
+  <p>The volcano erupted early on Monday, sending ash, smoke and lava across the valley.</p>
+  <p>Residents were evacuated, and officials said no injuries had been reported so far.</p>
+  <div class="related-articles">
+    <p>Related: <a href="/a">Earthquake hits coastal town after heavy storms</a></p>
+  </div>
+</div>
+<div class="sidebar"><p>Popular stories about celebrities, football and weather forecasts.</p></div>
+<div class="comments"><p>Great article, thanks for sharing this with everyone here!</p></div>
+</body></html>`
+
+func TestExtractReadableContent(t *testing.T) {
+	doc, err := ParseHTMLDocument(newsPageHTML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content := doc.ExtractReadableContent()
+	if !strings.Contains(content, "volcano erupted") || !strings.Contains(content, "evacuated") {
+		t.Errorf("expected article paragraphs, got '%s'", content)
+	}
+	for _, noise := range []string{"Earthquake", "celebrities", "Great article", "Sports"} {
+		if strings.Contains(content, noise) {
+			t.Errorf("unexpected boilerplate '%s' in content: %s", noise, content)
+		}
+	}
+}
+
+func TestExtractReadableContent_FallbackWithoutParagraphs(t *testing.T) {
+	doc, err := ParseHTMLDocument(`<html><body><nav>Menu</nav><h1>Short</h1></body></html>`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content := doc.ExtractReadableContent(); content != "Short" {
+		t.Errorf("expected 'Short', got '%s'", content)
+	}
+}
+
+func TestNewDocumentParser(t *testing.T) {
+	doc, err := ParseHTMLDocument(newsPageHTML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := NewDocumentParser("readability").(ReadabilityParser); !ok {
+		t.Error("expected ReadabilityParser for 'readability'")
+	}
+	p := NewDocumentParser("")
+	if _, ok := p.(BoilerplateParser); !ok {
+		t.Error("expected BoilerplateParser by default")
+	}
+	title, _ := p.ParseTitle(doc.Doc)
+	if title != "News" {
+		t.Errorf("expected 'News', got '%s'", title)
+	}
+	content, _ := p.ParseMainContent(doc.Doc)
+	if !strings.Contains(content, "Earthquake") {
+		t.Errorf("boilerplate parser should keep non-semantic blocks, got '%s'", content)
+	}
+}
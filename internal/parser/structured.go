@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+	"github.com/xshoji/go-keywordminer/pkg/utils"
+)
+
+// 構造化データの形式
+const (
+	// FormatJSONLD は <script type="application/ld+json"> のJSON-LD
+	FormatJSONLD = "json-ld"
+	// FormatMicrodata は itemscope / itemprop 属性のMicrodata
+	FormatMicrodata = "microdata"
+	// FormatRDFa は typeof / property 属性のRDFa
+	FormatRDFa = "rdfa"
+)
+
+// structuredDataProperties は構造化データから抽出するプロパティ（schema.org のプロパティ名）
+var structuredDataProperties = []string{
+	"name", "headline", "keywords", "articleSection", "about", "mentions", "brand", "category",
+}
+
+// FetchStructuredData はJSON-LD（@graph 配列を含む）・Microdata・RDFa から schema.org のオブジェクトを抽出します
+// キーワードに関係する項目（name・headline・keywords など）のいずれかを持つオブジェクトのみ返します
+func (h *HTMLDocument) FetchStructuredData() []types.StructuredData {
+	var result []types.StructuredData
+	result = append(result, h.fetchJSONLD()...)
+	result = append(result, h.fetchMicrodata()...)
+	result = append(result, h.fetchRDFa()...)
+	return result
+}
+
+// fetchJSONLD はJSON-LDのオブジェクトを抽出します
+func (h *HTMLDocument) fetchJSONLD() []types.StructuredData {
+	var result []types.StructuredData
+	h.Doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return
+		}
+		for _, node := range jsonLDNodes(data) {
+			props := map[string][]string{}
+			for _, key := range structuredDataProperties {
+				props[key] = jsonLDNames(node[key])
+			}
+			if item, ok := structuredItem(FormatJSONLD, jsonLDNames(node["@type"]), props); ok {
+				result = append(result, item)
+			}
+		}
+	})
+	return result
+}
+
+// structuredItem は型とプロパティの値から StructuredData を生成します（該当項目がない場合はfalse）
+func structuredItem(format string, itemTypes []string, props map[string][]string) (types.StructuredData, bool) {
+	item := types.StructuredData{
+		Format:         format,
+		Type:           strings.Join(utils.UniqueStrings(itemTypes), ", "),
+		Name:           strings.Join(utils.UniqueStrings(props["name"]), " "),
+		Headline:       strings.Join(utils.UniqueStrings(props["headline"]), " "),
+		Keywords:       splitKeywords(props["keywords"]),
+		ArticleSection: utils.UniqueStrings(props["articleSection"]),
+		About:          utils.UniqueStrings(props["about"]),
+		Mentions:       utils.UniqueStrings(props["mentions"]),
+		Brand:          strings.Join(utils.UniqueStrings(props["brand"]), " "),
+		Category:       utils.UniqueStrings(props["category"]),
+	}
+	ok := item.Name != "" || item.Headline != "" || len(item.Keywords) > 0 || len(item.ArticleSection) > 0 ||
+		len(item.About) > 0 || len(item.Mentions) > 0 || item.Brand != "" || len(item.Category) > 0
+	return item, ok
+}
+
+// jsonLDNodes はJSON-LDのトップレベル（配列・@graph）から @type を持つオブジェクトを返します
+func jsonLDNodes(v interface{}) []map[string]interface{} {
+	var nodes []map[string]interface{}
+	switch value := v.(type) {
+	case []interface{}:
+		for _, item := range value {
+			nodes = append(nodes, jsonLDNodes(item)...)
+		}
+	case map[string]interface{}:
+		if _, ok := value["@type"]; ok {
+			nodes = append(nodes, value)
+		}
+		if graph, ok := value["@graph"]; ok {
+			nodes = append(nodes, jsonLDNodes(graph)...)
+		}
+	}
+	return nodes
+}
+
+// jsonLDNames は文字列・文字列の配列・name を持つオブジェクト（Thing・Brand など）から値を取り出します
+func jsonLDNames(v interface{}) []string {
+	var names []string
+	switch value := v.(type) {
+	case string:
+		if value = utils.NormalizeSpace(value); value != "" {
+			names = append(names, value)
+		}
+	case []interface{}:
+		for _, item := range value {
+			names = append(names, jsonLDNames(item)...)
+		}
+	case map[string]interface{}:
+		names = append(names, jsonLDNames(value["name"])...)
+	}
+	return utils.UniqueStrings(names)
+}
+
+// splitKeywords は keywords（カンマ区切りの文字列または複数の値）を1件ずつに分割します
+func splitKeywords(values []string) []string {
+	var keywords []string
+	for _, value := range values {
+		for _, keyword := range strings.Split(value, ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+		}
+	}
+	return utils.UniqueStrings(keywords)
+}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFetchStructuredData_JSONLD(t *testing.T) {
+	html := `<html><head>
+	<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+		{"@type":"WebSite","@id":"https://example.com/#website","url":"https://example.com/"},
+		{"@type":["NewsArticle","Article"],"headline":"Go 1.23 released",
+			"keywords":"golang, iterators ,release","articleSection":["Programming","Go"],
+			"about":{"@type":"Thing","name":"Go programming language"},
+			"mentions":[{"@type":"Person","name":"Rob Pike"},"Gophers"]}]}</script>
+	<script type="application/ld+json">[{"@type":"Organization","name":"Example News"}]</script>
+	<script type="application/ld+json">{ invalid json</script>
+	</head><body></body></html>`
+	doc, err := ParseHTMLDocument(html)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := doc.FetchStructuredData()
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items (WebSite without fields skipped), got %+v", items)
+	}
+	article := items[0]
+	if article.Format != FormatJSONLD || article.Type != "NewsArticle, Article" || article.Headline != "Go 1.23 released" {
+		t.Errorf("unexpected article: %+v", article)
+	}
+	if !reflect.DeepEqual(article.Keywords, []string{"golang", "iterators", "release"}) {
+		t.Errorf("unexpected keywords: %+v", article.Keywords)
+	}
+	if !reflect.DeepEqual(article.ArticleSection, []string{"Programming", "Go"}) {
+		t.Errorf("unexpected article sections: %+v", article.ArticleSection)
+	}
+	if !reflect.DeepEqual(article.About, []string{"Go programming language"}) || !reflect.DeepEqual(article.Mentions, []string{"Rob Pike", "Gophers"}) {
+		t.Errorf("unexpected about/mentions: %+v %+v", article.About, article.Mentions)
+	}
+	if items[1].Type != "Organization" || items[1].Name != "Example News" {
+		t.Errorf("unexpected organization: %+v", items[1])
+	}
+}
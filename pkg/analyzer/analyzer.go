@@ -1,7 +1,11 @@
 package analyzer
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"time"
 
@@ -23,24 +27,125 @@ type PageData struct {
 }
 
 type Analyzer struct {
-	URL          string
+	URL     string
+	Charset string
+	// FetchAttempts は再試行を含むHTTPリクエストの試行回数（HTMLから生成した場合は0）
+	FetchAttempts int
+	// Truncated はレスポンスボディが MaxBodyBytes で切り詰められた場合にtrue
+	Truncated bool
+	// FetchInfo はリダイレクトやレスポンスヘッダなどのHTTP取得情報（HTMLから生成した場合はnil）
+	FetchInfo    *types.FetchInfo
 	responseBody []byte
 	doc          *parser.HTMLDocument
 	Config       config.Config
 }
 
 func NewAnalyzer(url string, cfg config.Config) (*Analyzer, error) {
-	res, err := fetcher.FetchURL(url, int(cfg.Timeout.Seconds()))
+	return NewAnalyzerContext(context.Background(), url, cfg)
+}
+
+// NewAnalyzerContext はcontextによるキャンセル・期限付きでページを取得し、Analyzerを生成します
+// Configのプロキシ・TLS設定が反映されます
+func NewAnalyzerContext(ctx context.Context, url string, cfg config.Config) (*Analyzer, error) {
+	f, err := fetcher.NewFetcher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewAnalyzerWithFetcher(ctx, url, cfg, f)
+}
+
+// NewAnalyzerWithClient は共有のHTTPクライアントでページを取得し、Analyzerを生成します
+// 多数のURLを解析する際に接続を使い回すために使用します（nilの場合はConfigのプロキシ・TLS設定に従う共有のTransportを使用）
+func NewAnalyzerWithClient(ctx context.Context, url string, cfg config.Config, client *http.Client) (*Analyzer, error) {
+	f, err := fetcher.NewFetcherWithClient(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	return NewAnalyzerWithFetcher(ctx, url, cfg, f)
+}
+
+// NewAnalyzerWithFetcher は指定したPageFetcherでページを取得し、Analyzerを生成します
+// types.ContextPageFetcher を実装するFetcherの場合はcontextとContent-Typeの文字コード、取得情報も使用します
+// Config.Hreflang を指定した場合は、該当する言語・地域の代替ページを取得して解析します
+func NewAnalyzerWithFetcher(ctx context.Context, url string, cfg config.Config, f types.PageFetcher) (*Analyzer, error) {
+	a, err := fetchAnalyzer(ctx, url, cfg, f)
+	if err != nil || cfg.Hreflang == "" {
+		return a, err
+	}
+	variant := a.FindAlternate(cfg.Hreflang)
+	if variant == "" || variant == a.URL {
+		return a, nil
+	}
+	return fetchAnalyzer(ctx, variant, cfg, f)
+}
+
+// fetchAnalyzer はPageFetcherでページを取得し、Analyzerを生成します
+func fetchAnalyzer(ctx context.Context, url string, cfg config.Config, f types.PageFetcher) (*Analyzer, error) {
+	if cf, ok := f.(types.ContextPageFetcher); ok {
+		res, err := cf.FetchPage(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		return newAnalyzerFromResult(res, cfg)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := f.Fetch(url, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return newAnalyzerFromBody(body, url, "", cfg)
+}
+
+// newAnalyzerFromResult はHTTP取得結果からAnalyzerを生成します
+func newAnalyzerFromResult(res *types.FetchedPage, cfg config.Config) (*Analyzer, error) {
+	a, err := newAnalyzerFromBody(res.Body, res.URL, res.ContentType, cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.FetchAttempts = res.Attempts
+	a.Truncated = res.Truncated
+	a.FetchInfo = res.Info
+	return a, nil
+}
+
+// NewAnalyzerFromHTML はHTTP取得を行わず、HTMLのバイト列からAnalyzerを生成します
+// baseURL はページのURLとして扱われます（不明な場合は空文字で可）
+func NewAnalyzerFromHTML(html []byte, baseURL string, cfg config.Config) (*Analyzer, error) {
+	return newAnalyzerFromBody(html, baseURL, "", cfg)
+}
+
+// NewAnalyzerFromReader はファイルや標準入力などから読み込んだHTMLでAnalyzerを生成します
+func NewAnalyzerFromReader(r io.Reader, baseURL string, cfg config.Config) (*Analyzer, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read HTML: %w", err)
+	}
+	return NewAnalyzerFromHTML(body, baseURL, cfg)
+}
+
+// newAnalyzerFromBody はレスポンスボディの文字コードを変換・解析してAnalyzerを生成します
+func newAnalyzerFromBody(body []byte, url string, contentType string, cfg config.Config) (*Analyzer, error) {
+	// Content-Type・BOM・metaタグから文字コードを判定してUTF-8に変換
+	html, charset, err := parser.DecodeHTML(body, contentType)
 	if err != nil {
 		return nil, err
 	}
-	doc, err := parser.ParseHTMLDocument(string(res.Body))
+	doc, err := parser.ParseHTMLDocument(html)
 	if err != nil {
 		return nil, err
 	}
+	// URLに一致するルールの除外セレクタの要素は、すべてのキーワードソースから除くため解析前に取り除く
+	for _, rule := range config.MatchSiteRules(cfg.SiteRules, url) {
+		for _, selector := range rule.Exclude {
+			doc.RemoveSelection(selector)
+		}
+	}
 	return &Analyzer{
-		URL:          res.URL,
-		responseBody: res.Body,
+		URL:          url,
+		Charset:      charset,
+		responseBody: body,
 		doc:          doc,
 		Config:       cfg,
 	}, nil
@@ -59,16 +164,141 @@ func (a *Analyzer) FetchMetaTags() (map[string]string, error) {
 }
 
 func (a *Analyzer) FetchMainContent() (string, error) {
-	var content string
-	hTags := a.doc.FetchTags("h1")
-	hTags = append(hTags, a.doc.FetchTags("h2")...)
-	hTags = append(hTags, a.doc.FetchTags("h3")...)
-	for _, headingText := range hTags {
-		if headingText != "" {
-			content += headingText + " " + headingText + " " + headingText + " "
+	return parser.NewDocumentParser(a.Config.ContentExtractor).ParseMainContent(a.doc.Doc)
+}
+
+// fetchMainContentWithoutHeadings は見出し（h1〜h6）を除いたメインコンテンツを返します
+func (a *Analyzer) fetchMainContentWithoutHeadings() (string, error) {
+	doc := a.doc.WithoutSelection("h1, h2, h3, h4, h5, h6")
+	return parser.NewDocumentParser(a.Config.ContentExtractor).ParseMainContent(doc.Doc)
+}
+
+// FetchEmbeddedContent は __NEXT_DATA__・window.__NUXT__・<noscript>・JSON-LD の articleBody から本文テキストを抽出します
+func (a *Analyzer) FetchEmbeddedContent() string {
+	return a.doc.FetchEmbeddedContent()
+}
+
+// FetchStructuredData はJSON-LD・Microdata・RDFaの構造化データ（schema.org）を返します
+func (a *Analyzer) FetchStructuredData() []types.StructuredData {
+	return a.doc.FetchStructuredData()
+}
+
+// FetchHeadings は指定セレクタ（例: "h1", "h4, h5, h6"）の見出しテキストを返します
+func (a *Analyzer) FetchHeadings(selector string) []string {
+	var headings []string
+	for _, headingText := range a.doc.FetchTags(selector) {
+		if headingText = strings.TrimSpace(headingText); headingText != "" {
+			headings = append(headings, headingText)
+		}
+	}
+	return headings
+}
+
+// FetchLinks はページ内リンクを絶対URL（<base href> があればその基準、なければ Analyzer.URL 基準）にして返します
+// http/https 以外のリンクは除外し、フラグメント（#以降）は取り除きます
+func (a *Analyzer) FetchLinks() []string {
+	resolve := a.urlResolver()
+	var links []string
+	for _, href := range a.doc.FetchLinks() {
+		if link := resolve(href); link != "" {
+			links = append(links, link)
 		}
 	}
-	return content, nil
+	return links
+}
+
+// FetchBaseURL は <base href> を Analyzer.URL 基準で絶対URLにして返します（指定がない場合は空文字）
+func (a *Analyzer) FetchBaseURL() string {
+	href := a.doc.FetchBaseHref()
+	if href == "" {
+		return ""
+	}
+	base, err := neturl.Parse(a.URL)
+	if err != nil {
+		return ""
+	}
+	ref, err := neturl.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// FetchCanonicalURL は <link rel="canonical"> の絶対URLを返します（指定がない場合は空文字）
+func (a *Analyzer) FetchCanonicalURL() string {
+	return a.firstLinkRel("canonical")
+}
+
+// FetchAMPURL は <link rel="amphtml"> の絶対URLを返します（指定がない場合は空文字）
+func (a *Analyzer) FetchAMPURL() string {
+	return a.firstLinkRel("amphtml")
+}
+
+// FetchAlternates は hreflang を指定した代替ページを絶対URLにして返します
+func (a *Analyzer) FetchAlternates() []types.Alternate {
+	resolve := a.urlResolver()
+	var alternates []types.Alternate
+	for _, alt := range a.doc.FetchHreflangLinks() {
+		if u := resolve(alt.URL); u != "" {
+			alternates = append(alternates, types.Alternate{Hreflang: alt.Hreflang, URL: u})
+		}
+	}
+	return alternates
+}
+
+// FindAlternate は指定した hreflang の代替ページのURLを返します（該当するページがない場合は空文字）
+// 完全一致（大文字小文字は区別しない）を優先し、"en" のように地域を省略した場合は "en-US" なども対象にします
+func (a *Analyzer) FindAlternate(hreflang string) string {
+	alternates := a.FetchAlternates()
+	for _, alt := range alternates {
+		if strings.EqualFold(alt.Hreflang, hreflang) {
+			return alt.URL
+		}
+	}
+	if !strings.Contains(hreflang, "-") {
+		for _, alt := range alternates {
+			if lang, _, found := strings.Cut(alt.Hreflang, "-"); found && strings.EqualFold(lang, hreflang) {
+				return alt.URL
+			}
+		}
+	}
+	return ""
+}
+
+// firstLinkRel は rel 属性に指定した値を含む最初の <link> の絶対URLを返します
+func (a *Analyzer) firstLinkRel(rel string) string {
+	resolve := a.urlResolver()
+	for _, href := range a.doc.FetchLinkRel(rel) {
+		if u := resolve(href); u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// urlResolver はページ内のURLを絶対URLにする関数を返します
+// 変換後のURLが http/https 以外の場合は空文字を返し、フラグメントは取り除きます
+func (a *Analyzer) urlResolver() func(href string) string {
+	baseURL := a.URL
+	if b := a.FetchBaseURL(); b != "" {
+		baseURL = b
+	}
+	base, err := neturl.Parse(baseURL)
+	return func(href string) string {
+		if err != nil {
+			return ""
+		}
+		ref, refErr := neturl.Parse(href)
+		if refErr != nil {
+			return ""
+		}
+		u := base.ResolveReference(ref)
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return ""
+		}
+		u.Fragment = ""
+		return u.String()
+	}
 }
 
 func (a *Analyzer) CollectPageData() (*PageData, error) {
@@ -83,80 +313,128 @@ func (a *Analyzer) CollectPageData() (*PageData, error) {
 }
 
 func (a *Analyzer) GetTopKeywords(n int, stopWords map[string]int, normalizeKeyword func(string) string) ([]scoring.KeywordWithScore, error) {
+	return a.GetTopKeywordsContext(context.Background(), n, stopWords, normalizeKeyword)
+}
+
+// GetTopKeywordsContext はcontextのキャンセルを考慮して上位キーワードを返します
+func (a *Analyzer) GetTopKeywordsContext(ctx context.Context, n int, stopWords map[string]int, normalizeKeyword func(string) string) ([]scoring.KeywordWithScore, error) {
 	cfg := a.Config
-	weightMetaKeyword := cfg.ScoreWeights.MetaKeyword
-	weightTitle := cfg.ScoreWeights.Title
-	weightDesc := cfg.ScoreWeights.Description
-	weightMain := cfg.ScoreWeights.MainContent
+	weights := cfg.ScoreWeights
 	if n <= 0 {
 		n = cfg.MaxKeywords
 	}
 	scoreMap := map[string]int{}
 	originalMap := map[string]string{}
 
-	// タイトル
-	title, _ := a.FetchTitle()
-	if title != "" {
-		for _, k := range extractKeywords(title, stopWords, normalizeKeyword) {
+	// addScores はテキストから抽出したキーワードに重みを加算します
+	// （キャンセル後は以降のソースを処理しない）
+	var extractErr error
+	addScores := func(text string, weight int) {
+		if text == "" || weight == 0 || extractErr != nil {
+			return
+		}
+		keywords, err := extractKeywords(ctx, text, stopWords, normalizeKeyword)
+		if err != nil {
+			extractErr = err
+			return
+		}
+		for _, k := range keywords {
 			normKey := k
-			scoreMap[normKey] += weightTitle
+			scoreMap[normKey] += weight
 			if existing, ok := originalMap[normKey]; !ok || len(k) > len(existing) {
 				originalMap[normKey] = k
 			}
 		}
 	}
 
+	// タイトル
+	title, _ := a.FetchTitle()
+	addScores(title, weights.Title)
+
 	// メタキーワード
 	meta := a.doc.FetchMetaTags()
-	if keywords, ok := meta["keywords"]; ok {
-		for _, k := range extractKeywords(keywords, stopWords, normalizeKeyword) {
-			normKey := k
-			scoreMap[normKey] += weightMetaKeyword
-			if existing, ok := originalMap[normKey]; !ok || len(k) > len(existing) {
-				originalMap[normKey] = k
-			}
-		}
-	}
+	addScores(meta["keywords"], weights.MetaKeyword)
+
+	// 記事タグ・ニュースキーワード（それぞれ別ソースとして重み付け）
+	addScores(meta["article:tag"], weights.ArticleTag)
+	addScores(meta["news_keywords"], weights.NewsKeywords)
+
+	// 構造化データ（JSON-LD・Microdata・RDFa）
+	addScores(structuredDataText(a.FetchStructuredData()), weights.StructuredData)
 
 	// 説明文
 	desc := ""
 	if d, ok := meta["description"]; ok {
 		desc = d
 	}
-	if d, ok := meta["og:description"]; ok && len(d) > len(desc) {
-		desc = d
-	}
-	if desc != "" {
-		for _, k := range extractKeywords(desc, stopWords, normalizeKeyword) {
-			normKey := k
-			scoreMap[normKey] += weightDesc
-			if existing, ok := originalMap[normKey]; !ok || len(k) > len(existing) {
-				originalMap[normKey] = k
-			}
+	for _, key := range []string{"og:description", "twitter:description"} {
+		if d, ok := meta[key]; ok && len(d) > len(desc) {
+			desc = d
 		}
 	}
+	addScores(desc, weights.Description)
 
-	// メインコンテンツ
-	mainContent, _ := a.FetchMainContent()
-	if mainContent != "" {
-		for _, k := range extractKeywords(mainContent, stopWords, normalizeKeyword) {
-			normKey := k
-			scoreMap[normKey] += weightMain
-			if existing, ok := originalMap[normKey]; !ok || len(k) > len(existing) {
-				originalMap[normKey] = k
-			}
+	// 見出し（レベルごとに別ソースとして重み付け）
+	addScores(strings.Join(a.FetchHeadings("h1"), " "), weights.H1)
+	addScores(strings.Join(a.FetchHeadings("h2"), " "), weights.H2)
+	addScores(strings.Join(a.FetchHeadings("h3"), " "), weights.H3)
+	addScores(strings.Join(a.FetchHeadings("h4, h5, h6"), " "), weights.H4To6)
+
+	// 画像の alt・図のキャプション・title 属性・アンカーテキスト（画像中心のページ向けの低い重みのソース）
+	addScores(strings.Join(a.doc.FetchImageAlts(), " "), weights.ImageAlt)
+	addScores(strings.Join(a.doc.FetchFigcaptions(), " "), weights.Figcaption)
+	addScores(strings.Join(a.doc.FetchTitleAttributes(), " "), weights.TitleAttribute)
+	addScores(strings.Join(a.doc.FetchAnchorTexts(), " "), weights.AnchorText)
+
+	// メインコンテンツ（見出しは上でレベルごとに数えているため除く）
+	mainContent, _ := a.fetchMainContentWithoutHeadings()
+	addScores(mainContent, weights.MainContent)
+
+	// JavaScriptで描画するページで表示される本文がほとんどない場合は埋め込みデータを本文として使う
+	if parser.IsNearlyEmpty(mainContent) {
+		addScores(a.FetchEmbeddedContent(), weights.MainContent)
+	}
+
+	// URLに一致するルールで指定された要素（ルールごとの重み）
+	for _, rule := range config.MatchSiteRules(cfg.SiteRules, a.URL) {
+		for _, include := range rule.Include {
+			addScores(a.doc.FetchSelectionText(include.Selector), include.Weight)
 		}
 	}
 
+	if extractErr != nil {
+		return nil, extractErr
+	}
 	return scoring.RankKeywordsByScore(scoreMap, originalMap, n), nil
 }
 
+// structuredDataText は構造化データのうちキーワードの抽出対象とする項目を連結します
+// name はサイト名・組織名であることが多いため、商品（Product）の場合のみ対象とします
+func structuredDataText(items []types.StructuredData) string {
+	var texts []string
+	for _, item := range items {
+		if strings.Contains(item.Type, "Product") {
+			texts = append(texts, item.Name)
+		}
+		texts = append(texts, item.Headline, item.Brand)
+		texts = append(texts, item.Category...)
+		texts = append(texts, item.Keywords...)
+		texts = append(texts, item.ArticleSection...)
+		texts = append(texts, item.About...)
+		texts = append(texts, item.Mentions...)
+	}
+	return strings.TrimSpace(strings.Join(texts, " "))
+}
+
 // extractKeywords: 言語自動判定して適切な抽出関数を呼ぶ
-func extractKeywords(text string, stopWords map[string]int, normalizeKeyword func(string) string) []string {
+func extractKeywords(ctx context.Context, text string, stopWords map[string]int, normalizeKeyword func(string) string) ([]string, error) {
 	if language.ContainsJapanese(text) {
-		return japanese.ExtractJapaneseKeywords(text)
+		return japanese.ExtractJapaneseKeywordsContext(ctx, text)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	return english.ExtractEnglishKeywords(text, stopWords, normalizeKeyword)
+	return english.ExtractEnglishKeywords(text, stopWords, normalizeKeyword), nil
 }
 
 // ページ取得の分離
@@ -226,12 +504,16 @@ func (a *Analyzer) GetTopKeywordsWithDefaultConfig(n int) ([]scoring.KeywordWith
 	normalize := func(word string) string {
 		return english.NormalizeEnglishKeyword(word, pluralSingularMap, invariantWords)
 	}
-	mainContent, _ := a.FetchMainContent()
+	mainContent, _ := a.fetchMainContentWithoutHeadings()
 	return ExtractKeywordsWithFrequency(mainContent, stopWords, normalize), nil
 }
 
 // stopWords, normalizeKeyword をConfigから自動で利用するバージョン
 func (a *Analyzer) GetTopKeywordsAuto(n int) ([]scoring.KeywordWithScore, error) {
+	return a.getTopKeywordsAuto(context.Background(), n)
+}
+
+func (a *Analyzer) getTopKeywordsAuto(ctx context.Context, n int) ([]scoring.KeywordWithScore, error) {
 	cfg := a.Config
 	stopWords := cfg.EnglishStopWords
 	pluralSingularMap := cfg.PluralSingularMap
@@ -239,12 +521,21 @@ func (a *Analyzer) GetTopKeywordsAuto(n int) ([]scoring.KeywordWithScore, error)
 	normalize := func(word string) string {
 		return english.NormalizeEnglishKeyword(word, pluralSingularMap, invariantWords)
 	}
-	return a.GetTopKeywords(n, stopWords, normalize)
+	return a.GetTopKeywordsContext(ctx, n, stopWords, normalize)
 }
 
 // GetAnalysisResult はウェブページの解析結果を返します
 func (a *Analyzer) GetAnalysisResult(maxKeywords int) (*types.AnalysisResult, error) {
-	result := &types.AnalysisResult{}
+	return a.GetAnalysisResultContext(context.Background(), maxKeywords)
+}
+
+// GetAnalysisResultContext はcontextのキャンセルを考慮してウェブページの解析結果を返します
+// キャンセル・期限切れの場合は部分的な結果ではなくcontextのエラーを返します
+func (a *Analyzer) GetAnalysisResultContext(ctx context.Context, maxKeywords int) (*types.AnalysisResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := &types.AnalysisResult{URL: a.URL, Charset: a.Charset, FetchAttempts: a.FetchAttempts, Truncated: a.Truncated, Fetch: a.FetchInfo}
 	var lastErr error
 
 	// タイトルを取得
@@ -263,8 +554,20 @@ func (a *Analyzer) GetAnalysisResult(maxKeywords int) (*types.AnalysisResult, er
 		result.MetaTags = meta
 	}
 
+	// canonical・hreflang・AMP・<base href> を取得
+	result.Canonical = a.FetchCanonicalURL()
+	result.AMPURL = a.FetchAMPURL()
+	result.Alternates = a.FetchAlternates()
+	result.BaseURL = a.FetchBaseURL()
+
+	// 構造化データを取得
+	result.StructuredData = a.FetchStructuredData()
+
 	// キーワードを取得
-	keywordsWithScores, err := a.GetTopKeywordsAuto(maxKeywords)
+	keywordsWithScores, err := a.getTopKeywordsAuto(ctx, maxKeywords)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		lastErr = err
 	} else if len(keywordsWithScores) > 0 {
@@ -1,11 +1,20 @@
 package analyzer
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/xshoji/go-keywordminer/internal/fetcher"
+	"github.com/xshoji/go-keywordminer/pkg/config"
+	"github.com/xshoji/go-keywordminer/pkg/types"
+	"golang.org/x/text/encoding/japanese"
 )
 
 func TestFetchPage(t *testing.T) {
@@ -65,3 +74,267 @@ func TestExtractKeywords_Japanese(t *testing.T) {
 		t.Error("expected some keywords, got none")
 	}
 }
+
+func TestNewAnalyzer_ShiftJIS(t *testing.T) {
+	body, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte("<html><head><title>形態素解析の基礎</title></head><body></body></html>"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=Shift_JIS")
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	a, err := NewAnalyzer(ts.URL, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewAnalyzer error: %v", err)
+	}
+	title, _ := a.FetchTitle()
+	if title != "形態素解析の基礎" {
+		t.Errorf("expected decoded title, got '%s'", title)
+	}
+	result, _ := a.GetAnalysisResult(5)
+	if result.Charset != "shift_jis" {
+		t.Errorf("expected charset shift_jis, got '%s'", result.Charset)
+	}
+	if result.Fetch == nil || result.Fetch.StatusCode != http.StatusOK || result.Fetch.Bytes != int64(len(body)) {
+		t.Errorf("unexpected fetch info: %+v", result.Fetch)
+	}
+}
+
+func TestNewAnalyzerContext_Canceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>t</title></head></html>"))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewAnalyzerContext(ctx, ts.URL, config.DefaultConfig()); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	a, err := NewAnalyzerContext(context.Background(), ts.URL, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewAnalyzerContext error: %v", err)
+	}
+	if _, err := a.GetAnalysisResultContext(ctx, 5); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := a.GetTopKeywordsContext(ctx, 5, map[string]int{}, func(s string) string { return s }); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNewAnalyzer_RetryAttempts(t *testing.T) {
+	failures := 1
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("<html><head><title>Retry</title></head><body></body></html>"))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Retry = config.RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	a, err := NewAnalyzer(ts.URL, cfg)
+	if err != nil {
+		t.Fatalf("NewAnalyzer error: %v", err)
+	}
+	result, _ := a.GetAnalysisResult(5)
+	if result.Title != "Retry" || result.FetchAttempts != 2 {
+		t.Errorf("expected title after retry with 2 attempts, got '%s' (%d attempts)", result.Title, result.FetchAttempts)
+	}
+}
+
+func TestNewAnalyzer_Truncated(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><head><title>Large page</title></head><body>" + strings.Repeat("<p>filler text</p>", 1000) + "</body></html>"))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.IgnoreRobotsTxt = true
+	cfg.MaxBodyBytes = 1024
+	a, err := NewAnalyzer(ts.URL, cfg)
+	if err != nil {
+		t.Fatalf("NewAnalyzer error: %v", err)
+	}
+	result, _ := a.GetAnalysisResult(5)
+	if !result.Truncated {
+		t.Error("expected truncated result")
+	}
+	if result.Title != "Large page" {
+		t.Errorf("expected title from the truncated body, got '%s'", result.Title)
+	}
+}
+
+// stubFetcher はボディのみを返す types.PageFetcher
+type stubFetcher struct {
+	body []byte
+}
+
+func (s stubFetcher) Fetch(url string, timeout time.Duration) ([]byte, error) {
+	return s.body, nil
+}
+
+func TestNewAnalyzerWithFetcher(t *testing.T) {
+	f := stubFetcher{body: []byte("<html><head><title>Injected</title></head><body><p>golang</p></body></html>")}
+	a, err := NewAnalyzerWithFetcher(context.Background(), "https://example.com/", config.DefaultConfig(), f)
+	if err != nil {
+		t.Fatalf("NewAnalyzerWithFetcher error: %v", err)
+	}
+	result, _ := a.GetAnalysisResult(5)
+	if result.URL != "https://example.com/" || result.Title != "Injected" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewAnalyzerWithFetcher(ctx, "https://example.com/", config.DefaultConfig(), f); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// pageFetcher はShift_JISのボディとContent-Typeを返す types.ContextPageFetcher
+type pageFetcher struct{}
+
+func (pageFetcher) Fetch(url string, timeout time.Duration) ([]byte, error) {
+	return nil, errors.New("Fetch should not be called")
+}
+
+func (pageFetcher) FetchPage(ctx context.Context, url string) (*types.FetchedPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte("<html><head><title>外部の取得</title></head></html>"))
+	if err != nil {
+		return nil, err
+	}
+	return &types.FetchedPage{
+		URL:         url + "final",
+		ContentType: "text/html; charset=Shift_JIS",
+		Body:        body,
+		Attempts:    2,
+		Info:        &types.FetchInfo{StatusCode: http.StatusOK, FinalURL: url + "final"},
+	}, nil
+}
+
+func TestNewAnalyzerWithFetcher_ContextPageFetcher(t *testing.T) {
+	a, err := NewAnalyzerWithFetcher(context.Background(), "https://example.com/", config.DefaultConfig(), pageFetcher{})
+	if err != nil {
+		t.Fatalf("NewAnalyzerWithFetcher error: %v", err)
+	}
+	result, _ := a.GetAnalysisResult(5)
+	if result.Title != "外部の取得" || result.Charset != "shift_jis" || result.URL != "https://example.com/final" {
+		t.Errorf("expected Content-Type charset and final URL to be used, got %+v", result)
+	}
+	if a.FetchAttempts != 2 || a.FetchInfo == nil || a.FetchInfo.StatusCode != http.StatusOK {
+		t.Errorf("expected fetch info from FetchPage, got %d %+v", a.FetchAttempts, a.FetchInfo)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewAnalyzerWithFetcher(ctx, "https://example.com/", config.DefaultConfig(), pageFetcher{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// mapFetcher はURLごとのボディを返す types.PageFetcher
+type mapFetcher map[string]string
+
+func (m mapFetcher) Fetch(url string, timeout time.Duration) ([]byte, error) {
+	body, ok := m[url]
+	if !ok {
+		return nil, errors.New("not found: " + url)
+	}
+	return []byte(body), nil
+}
+
+func TestNewAnalyzerWithFetcher_Hreflang(t *testing.T) {
+	f := mapFetcher{
+		"https://example.com/en/": `<html><head><title>English</title>
+			<link rel="alternate" hreflang="en" href="/en/"><link rel="alternate" hreflang="de-DE" href="/de/"></head></html>`,
+		"https://example.com/de/": `<html><head><title>Deutsch</title></head></html>`,
+	}
+	for _, tc := range []struct {
+		hreflang string
+		title    string
+	}{{"", "English"}, {"de", "Deutsch"}, {"en", "English"}, {"fr", "English"}} {
+		cfg := config.DefaultConfig()
+		cfg.Hreflang = tc.hreflang
+		a, err := NewAnalyzerWithFetcher(context.Background(), "https://example.com/en/", cfg, f)
+		if err != nil {
+			t.Fatalf("hreflang '%s': unexpected error: %v", tc.hreflang, err)
+		}
+		if title, _ := a.FetchTitle(); title != tc.title {
+			t.Errorf("hreflang '%s': expected '%s', got '%s'", tc.hreflang, tc.title, title)
+		}
+	}
+}
+
+// newReplayFetcher は testdata/fixtures のレスポンスを再生するFetcherを返します
+// フィクスチャは fetcher.NewFixtureFetcher(cfg, dir, fetcher.FixtureRecord) で記録できます
+func newReplayFetcher(t *testing.T, cfg config.Config) *fetcher.Fetcher {
+	t.Helper()
+	f, err := fetcher.NewFixtureFetcher(cfg, filepath.Join("testdata", "fixtures"), fetcher.FixtureReplay)
+	if err != nil {
+		t.Fatalf("NewFixtureFetcher error: %v", err)
+	}
+	return f
+}
+
+func TestGetAnalysisResult_Replay(t *testing.T) {
+	cfg := config.DefaultConfig()
+	f := newReplayFetcher(t, cfg)
+
+	a, err := NewAnalyzerWithFetcher(context.Background(), "https://example.com/old-concurrency", cfg, f)
+	if err != nil {
+		t.Fatalf("NewAnalyzerWithFetcher error: %v", err)
+	}
+	result, err := a.GetAnalysisResult(10)
+	if err != nil {
+		t.Fatalf("GetAnalysisResult error: %v", err)
+	}
+	if result.URL != "https://example.com/articles/concurrency" || result.Title != "Go Concurrency Patterns" {
+		t.Errorf("unexpected result: %s '%s'", result.URL, result.Title)
+	}
+	if len(result.Keywords) == 0 || result.Keywords[0].Keyword != "concurrency" {
+		t.Errorf("expected 'concurrency' as the top keyword, got %+v", result.Keywords)
+	}
+	if result.Fetch == nil || len(result.Fetch.Redirects) != 1 || result.Fetch.ETag != `"concurrency-v1"` {
+		t.Errorf("unexpected fetch info: %+v", result.Fetch)
+	}
+
+	// robots.txtで禁止されたURLとエラーページも再生できる
+	if _, err := NewAnalyzerWithFetcher(context.Background(), "https://example.com/private/page", cfg, f); !errors.Is(err, fetcher.ErrDisallowedByRobots) {
+		t.Errorf("expected ErrDisallowedByRobots, got %v", err)
+	}
+	var statusErr *fetcher.ErrHTTPStatus
+	if _, err := NewAnalyzerWithFetcher(context.Background(), "https://example.com/missing", cfg, f); !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("expected ErrHTTPStatus 404, got %v", err)
+	}
+}
+
+func TestNewAnalyzerWithClient_UsesFetcher(t *testing.T) {
+	// 共有クライアントを指定してもフィクスチャの再生などConfigの取得設定が反映される
+	cfg := config.DefaultConfig()
+	cfg.FixtureDir = filepath.Join("testdata", "fixtures")
+	cfg.FixtureMode = fetcher.FixtureReplay
+	a, err := NewAnalyzerWithClient(context.Background(), "https://example.com/old-concurrency", cfg, &http.Client{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewAnalyzerWithClient error: %v", err)
+	}
+	if title, _ := a.FetchTitle(); a.URL != "https://example.com/articles/concurrency" || title != "Go Concurrency Patterns" {
+		t.Errorf("unexpected analyzer: %s '%s'", a.URL, title)
+	}
+}
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/xshoji/go-keywordminer/internal/parser"
@@ -12,17 +13,12 @@ type dummyNormalizer struct{}
 func (d dummyNormalizer) NormalizeKeyword(word string) string { return word }
 
 // テスト用: HTML文字列からAnalyzerを生成
-func NewAnalyzerFromHTML(html string, cfg config.Config) *Analyzer {
-	doc, err := parser.ParseHTMLDocument(html)
+func mustNewAnalyzerFromHTML(html string, cfg config.Config) *Analyzer {
+	a, err := NewAnalyzerFromHTML([]byte(html), "dummy", cfg)
 	if err != nil {
 		panic(err)
 	}
-	return &Analyzer{
-		URL:          "dummy",
-		responseBody: []byte(html),
-		doc:          doc,
-		Config:       cfg,
-	}
+	return a
 }
 
 // goquery.Documentのラッパーをテスト用に生成
@@ -32,7 +28,7 @@ func ParseHTMLDocumentForTest(html string) (*parser.HTMLDocument, error) {
 
 func TestAnalyzer_FetchTitleAndMeta(t *testing.T) {
 	html := `<html><head><title>TestTitle</title><meta name="description" content="desc"><meta name="keywords" content="go, test"></head><body><h1>見出し</h1></body></html>`
-	doc := NewAnalyzerFromHTML(html, config.DefaultConfig())
+	doc := mustNewAnalyzerFromHTML(html, config.DefaultConfig())
 	title, _ := doc.FetchTitle()
 	if title != "TestTitle" {
 		t.Errorf("expected 'TestTitle', got '%s'", title)
@@ -48,7 +44,7 @@ func TestAnalyzer_FetchTitleAndMeta(t *testing.T) {
 
 func TestAnalyzer_GetTopKeywords_English(t *testing.T) {
 	html := `<html><head><title>Go Test</title><meta name="keywords" content="go, test, code"></head><body><h1>Go Test</h1></body></html>`
-	doc := NewAnalyzerFromHTML(html, config.DefaultConfig())
+	doc := mustNewAnalyzerFromHTML(html, config.DefaultConfig())
 	stopWords := map[string]int{"the": 0}
 	keywords, err := doc.GetTopKeywords(3, stopWords, func(s string) string { return s })
 	if err != nil {
@@ -61,7 +57,7 @@ func TestAnalyzer_GetTopKeywords_English(t *testing.T) {
 
 func TestAnalyzer_GetTopKeywords_Japanese(t *testing.T) {
 	html := `<html><head><title>日本語 テスト</title></head><body><h1>日本語 テスト</h1></body></html>`
-	doc := NewAnalyzerFromHTML(html, config.DefaultConfig())
+	doc := mustNewAnalyzerFromHTML(html, config.DefaultConfig())
 	keywords, err := doc.GetTopKeywords(3, map[string]int{}, func(s string) string { return s })
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -70,3 +66,287 @@ func TestAnalyzer_GetTopKeywords_Japanese(t *testing.T) {
 		t.Error("expected keywords, got none")
 	}
 }
+
+func TestAnalyzer_FetchMainContent_IncludesBody(t *testing.T) {
+	html := `<html><head><title>t</title></head><body><nav>Menu</nav><h1>Heading</h1><p>Paragraph about gophers.</p></body></html>`
+	doc := mustNewAnalyzerFromHTML(html, config.DefaultConfig())
+	content, _ := doc.FetchMainContent()
+	if !strings.Contains(content, "gophers") {
+		t.Errorf("expected paragraph text in main content, got '%s'", content)
+	}
+	if strings.Contains(content, "Menu") {
+		t.Errorf("expected nav text to be removed, got '%s'", content)
+	}
+}
+
+func TestAnalyzer_FetchMainContent_Readability(t *testing.T) {
+	html := `<html><body>
+	<div class="content"><p>Gophers love concurrency, channels, and simple tooling in Go.</p></div>
+	<div class="sidebar"><p>Trending now: celebrity gossip, football scores and more.</p></div>
+	</body></html>`
+	cfg := config.DefaultConfig()
+	cfg.ContentExtractor = config.ContentExtractorReadability
+	doc := mustNewAnalyzerFromHTML(html, cfg)
+	content, _ := doc.FetchMainContent()
+	if !strings.Contains(content, "Gophers") {
+		t.Errorf("expected article text in main content, got '%s'", content)
+	}
+	if strings.Contains(content, "celebrity") {
+		t.Errorf("expected sidebar text to be removed, got '%s'", content)
+	}
+}
+
+func TestAnalyzer_GetTopKeywords_HeadingLevels(t *testing.T) {
+	html := `<html><body><h1>Gopher</h1><h3>Rustacean</h3><h5>Pythonista</h5></body></html>`
+	doc := mustNewAnalyzerFromHTML(html, config.DefaultConfig())
+	keywords, err := doc.GetTopKeywords(3, map[string]int{}, func(s string) string { return s })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	order := []string{"gopher", "rustacean", "pythonista"}
+	if len(keywords) != len(order) {
+		t.Fatalf("expected %d keywords, got %+v", len(order), keywords)
+	}
+	for i, k := range order {
+		if keywords[i].Keyword != k {
+			t.Errorf("expected '%s' at rank %d, got %+v", k, i, keywords)
+		}
+	}
+}
+
+func TestAnalyzer_GetTopKeywords_HeadingsNotInMainContent(t *testing.T) {
+	html := `<html><body><h1>Gopher</h1><p>Rust guide</p></body></html>`
+	cfg := config.DefaultConfig()
+	doc := mustNewAnalyzerFromHTML(html, cfg)
+	keywords, err := doc.GetTopKeywords(5, map[string]int{}, func(s string) string { return s })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 見出しのみにある語は見出しの重みだけで数え、本文の重みでは数えない
+	expected := cfg.ScoreWeights.H1
+	if len(keywords) == 0 || keywords[0].Keyword != "gopher" || keywords[0].Score != expected {
+		t.Errorf("expected 'gopher' with score %d, got %+v", expected, keywords)
+	}
+}
+
+func TestNewAnalyzerFromReader(t *testing.T) {
+	html := `<html><head><meta charset="euc-jp"><title>Offline</title></head><body><p>Archived page</p></body></html>`
+	a, err := NewAnalyzerFromReader(strings.NewReader(html), "https://example.com/archive", config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.URL != "https://example.com/archive" {
+		t.Errorf("expected base URL, got '%s'", a.URL)
+	}
+	result, err := a.GetAnalysisResult(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Title != "Offline" || result.Charset != "euc-jp" || len(result.Keywords) == 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestAnalyzer_FetchLinks(t *testing.T) {
+	html := `<html><body><a href="/docs#intro">Docs</a><a href="child">Child</a><a href="mailto:a@example.com">Mail</a><a href="https://other.example/">Other</a></body></html>`
+	a, err := NewAnalyzerFromHTML([]byte(html), "https://example.com/blog/", config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	links := a.FetchLinks()
+	expected := []string{"https://example.com/docs", "https://example.com/blog/child", "https://other.example/"}
+	if len(links) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, links)
+	}
+	for i := range expected {
+		if links[i] != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], links[i])
+		}
+	}
+}
+
+func TestAnalyzer_GetTopKeywords_EmbeddedData(t *testing.T) {
+	// 表示される本文がない SPA のシェルでも __NEXT_DATA__ からキーワードを抽出する
+	html := `<html><head><title>App</title></head><body><div id="__next">Loading</div>
+	<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{
+		"title":"Terraform modules explained","body":"Terraform modules group resources. Terraform state tracks modules."}}}</script>
+	</body></html>`
+	a := mustNewAnalyzerFromHTML(html, config.DefaultConfig())
+	keywords, err := a.GetTopKeywords(10, map[string]int{}, func(s string) string { return s })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := map[string]bool{}
+	for _, k := range keywords {
+		found[k.Keyword] = true
+	}
+	if !found["terraform"] || !found["modules"] {
+		t.Errorf("expected keywords from embedded data, got %+v", keywords)
+	}
+}
+
+func TestAnalyzer_GetTopKeywords_ArticleTagAndNewsKeywords(t *testing.T) {
+	html := `<html><head>
+	<meta property="article:tag" content="Kubernetes"><meta property="article:tag" content="Helm">
+	<meta name="news_keywords" content="Cloud">
+	</head><body><p>Deploy applications.</p></body></html>`
+	cfg := config.DefaultConfig()
+	a := mustNewAnalyzerFromHTML(html, cfg)
+	keywords, err := a.GetTopKeywords(10, map[string]int{}, func(s string) string { return s })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scores := map[string]int{}
+	for _, k := range keywords {
+		scores[k.Keyword] = k.Score
+	}
+	if scores["kubernetes"] != cfg.ScoreWeights.ArticleTag || scores["helm"] != cfg.ScoreWeights.ArticleTag {
+		t.Errorf("expected article:tag weight for tags, got %+v", keywords)
+	}
+	if scores["cloud"] != cfg.ScoreWeights.NewsKeywords {
+		t.Errorf("expected news_keywords weight for 'cloud', got %+v", keywords)
+	}
+}
+
+func TestAnalyzer_GetTopKeywords_StructuredData(t *testing.T) {
+	html := `<html><head><script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+		{"@type":"Article","keywords":["Kubernetes","Helm"],"articleSection":"Cloud"}]}</script>
+	</head><body><p>Deploy applications.</p></body></html>`
+	cfg := config.DefaultConfig()
+	a := mustNewAnalyzerFromHTML(html, cfg)
+	keywords, err := a.GetTopKeywords(10, map[string]int{}, func(s string) string { return s })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scores := map[string]int{}
+	for _, k := range keywords {
+		scores[k.Keyword] = k.Score
+	}
+	for _, k := range []string{"kubernetes", "helm", "cloud"} {
+		if scores[k] != cfg.ScoreWeights.StructuredData {
+			t.Errorf("expected structured data weight for '%s', got %+v", k, keywords)
+		}
+	}
+
+	result, err := a.GetAnalysisResult(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.StructuredData) != 1 || result.StructuredData[0].Type != "Article" {
+		t.Errorf("expected structured data in result, got %+v", result.StructuredData)
+	}
+}
+
+func TestAnalyzer_GetTopKeywords_MicrodataProduct(t *testing.T) {
+	html := `<html><body>
+	<div itemscope itemtype="https://schema.org/Product"><span itemprop="name">Espresso Grinder</span>
+		<span itemprop="brand" itemscope itemtype="https://schema.org/Brand"><span itemprop="name">Acme</span></span>
+		<meta itemprop="category" content="Kitchen"></div>
+	<div itemscope itemtype="https://schema.org/Organization"><span itemprop="name">Retailer</span></div>
+	</body></html>`
+	cfg := config.DefaultConfig()
+	cfg.ScoreWeights.MainContent = 0
+	a := mustNewAnalyzerFromHTML(html, cfg)
+	keywords, err := a.GetTopKeywords(10, map[string]int{}, func(s string) string { return s })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scores := map[string]int{}
+	for _, k := range keywords {
+		scores[k.Keyword] = k.Score
+	}
+	for _, k := range []string{"espresso", "grinder", "acme", "kitchen"} {
+		if scores[k] != cfg.ScoreWeights.StructuredData {
+			t.Errorf("expected structured data weight for '%s', got %+v", k, keywords)
+		}
+	}
+	if _, ok := scores["retailer"]; ok {
+		t.Errorf("expected organization name not to be scored, got %+v", keywords)
+	}
+}
+
+func TestAnalyzer_CanonicalAlternatesAndBase(t *testing.T) {
+	html := `<html><head><base href="/en/">
+	<link rel="canonical" href="articles/go#top"><link rel="amphtml" href="/amp/en/articles/go">
+	<link rel="alternate" hreflang="ja-JP" href="/ja/articles/go"><link rel="alternate" hreflang="x-default" href="articles/go">
+	</head><body><a href="about">About</a></body></html>`
+	a, err := NewAnalyzerFromHTML([]byte(html), "https://example.com/index.html", config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := a.GetAnalysisResult(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.BaseURL != "https://example.com/en/" || result.Canonical != "https://example.com/en/articles/go" || result.AMPURL != "https://example.com/amp/en/articles/go" {
+		t.Errorf("unexpected link info: %+v", result)
+	}
+	if len(result.Alternates) != 2 || result.Alternates[0].URL != "https://example.com/ja/articles/go" || result.Alternates[1].Hreflang != "x-default" {
+		t.Errorf("unexpected alternates: %+v", result.Alternates)
+	}
+	// 相対リンクは <base href> を基準にする
+	if links := a.FetchLinks(); len(links) != 1 || links[0] != "https://example.com/en/about" {
+		t.Errorf("unexpected links: %v", links)
+	}
+	if u := a.FindAlternate("ja"); u != "https://example.com/ja/articles/go" {
+		t.Errorf("expected 'ja' to match 'ja-JP', got '%s'", u)
+	}
+	if u := a.FindAlternate("fr"); u != "" {
+		t.Errorf("expected no alternate for 'fr', got '%s'", u)
+	}
+}
+
+func TestAnalyzer_GetTopKeywords_ImageAltAndCaptions(t *testing.T) {
+	// 本文がほとんどない画像ギャラリーでも alt・キャプション・title 属性からキーワードを抽出する
+	html := `<html><body>
+	<figure><img src="/1.jpg" alt="Lasagna"><figcaption>Lasagna</figcaption></figure>
+	<a href="/recipes/risotto" title="Risotto">Risotto</a>
+	</body></html>`
+	cfg := config.DefaultConfig()
+	cfg.ScoreWeights.MainContent = 0
+	a := mustNewAnalyzerFromHTML(html, cfg)
+	keywords, err := a.GetTopKeywords(10, map[string]int{}, func(s string) string { return s })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scores := map[string]int{}
+	for _, k := range keywords {
+		scores[k.Keyword] = k.Score
+	}
+	w := cfg.ScoreWeights
+	if scores["lasagna"] != w.ImageAlt+w.Figcaption {
+		t.Errorf("expected image alt and figcaption weights for 'lasagna', got %+v", keywords)
+	}
+	if scores["risotto"] != w.TitleAttribute+w.AnchorText {
+		t.Errorf("expected title attribute and anchor text weights for 'risotto', got %+v", keywords)
+	}
+}
+
+func TestAnalyzer_GetTopKeywords_SiteRules(t *testing.T) {
+	html := `<html><body><article><div class="article-body"><p>Sourdough fermentation.</p></div>
+	<div class="recommend"><p>Croissant Croissant Croissant</p></div></article></body></html>`
+	cfg := config.DefaultConfig()
+	cfg.SiteRules = []config.SiteRule{
+		{URL: "example.com/recipes/*", Include: []config.SelectorWeight{{Selector: ".article-body", Weight: 2}}, Exclude: []string{".recommend"}},
+		{URL: "other.example", Exclude: []string{".article-body"}},
+	}
+	a, err := NewAnalyzerFromHTML([]byte(html), "https://example.com/recipes/bread", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keywords, err := a.GetTopKeywords(10, map[string]int{}, func(s string) string { return s })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scores := map[string]int{}
+	for _, k := range keywords {
+		scores[k.Keyword] = k.Score
+	}
+	// 本文（重み1）に加えてルールの重み2が加算され、除外した要素は本文にも含まれない
+	if scores["sourdough"] != cfg.ScoreWeights.MainContent+2 {
+		t.Errorf("expected included selector weight for 'sourdough', got %+v", keywords)
+	}
+	if _, ok := scores["croissant"]; ok {
+		t.Errorf("expected excluded selector to be ignored, got %+v", keywords)
+	}
+}
@@ -65,23 +65,94 @@ var DefaultInvariantWords = map[string]bool{
 	"advice": true, "knowledge": true, "research": true, "data": true,
 }
 
+// 本文抽出方式
+const (
+	// ContentExtractorBoilerplate は定型要素（nav/header/footer等）を除去して本文を抽出します
+	ContentExtractorBoilerplate = "boilerplate"
+	// ContentExtractorReadability はコンテンツ密度スコアで本文ブロックを選んで抽出します
+	ContentExtractorReadability = "readability"
+)
+
 // Configに追加
 type Config struct {
 	Timeout           time.Duration
 	UserAgent         string
+	Headers           map[string]string
 	ScoreWeights      ScoreWeightConfig
 	MaxKeywords       int
 	IgnoreStopWords   bool
 	EnglishStopWords  map[string]int
 	PluralSingularMap map[string]string
 	InvariantWords    map[string]bool
+	ContentExtractor  string
+	// IgnoreRobotsTxt がtrueの場合はrobots.txtを確認しません（自サイトの解析用）
+	IgnoreRobotsTxt bool
+	// RequestsPerSecond は同一ホストへの1秒あたりの最大リクエスト数（0以下の場合は制限なし）
+	RequestsPerSecond float64
+	// HostBurst は同一ホストへ間隔を空けずに送れるリクエスト数
+	HostBurst int
+	// Retry は一時的な失敗（5xx, 429, 接続エラー）時の再試行ポリシー
+	Retry RetryPolicy
+	// MaxBodyBytes はレスポンスボディの最大サイズ（0以下の場合は無制限）
+	MaxBodyBytes int64
+	// FailOnLargeBody がtrueの場合は MaxBodyBytes を超えるレスポンスをエラーにします（falseの場合は切り詰めて解析）
+	FailOnLargeBody bool
+	// ProxyURL はリクエストに使用するプロキシ（空の場合は HTTP_PROXY / HTTPS_PROXY / NO_PROXY 環境変数に従う）
+	ProxyURL string
+	// CACertFile は追加で信頼するCA証明書（PEM形式）のファイルパス
+	CACertFile string
+	// InsecureSkipVerify がtrueの場合はTLS証明書を検証しません（ステージング環境用）
+	InsecureSkipVerify bool
+	// CacheDir はレスポンスを保存するディスクキャッシュのディレクトリ（空の場合はキャッシュしない）
+	CacheDir string
+	// CacheTTL はリクエストせずにキャッシュを使用する期間（0の場合は毎回ETag/Last-Modifiedで再検証）
+	CacheTTL time.Duration
+	// FixtureDir はレスポンスを記録・再生するフィクスチャのディレクトリ（空の場合は使用しない）
+	FixtureDir string
+	// FixtureMode は "replay"（フィクスチャから再生）または "record"（取得して記録）
+	FixtureMode string
+	// Hreflang を指定した場合は、取得したページの <link rel="alternate" hreflang> から該当する言語・地域のページを取得して解析します
+	// （"en" のように地域を省略した場合は "en-US" なども対象。該当するページがない場合は元のページを解析）
+	Hreflang string
+	// SiteRules はURLパターンごとのCSSセレクタによる抽出ルール（LoadSiteRules で読み込み）
+	SiteRules []SiteRule
+}
+
+// RetryPolicy はHTTP取得の再試行ポリシー
+// 待機時間は InitialBackoff から試行ごとに2倍にし、MaxBackoff を上限とします
+type RetryPolicy struct {
+	// MaxAttempts は初回を含む最大試行回数（1以下の場合は再試行しない）
+	MaxAttempts int
+	// InitialBackoff は1回目の再試行までの待機時間
+	InitialBackoff time.Duration
+	// MaxBackoff は待機時間の上限（Retry-Afterがこれを超える場合は再試行しない）
+	MaxBackoff time.Duration
+	// Jitter は待機時間に加えるランダムな揺らぎの割合（0.2 なら ±20%）
+	Jitter float64
 }
 
 type ScoreWeightConfig struct {
 	Title       int
 	MetaKeyword int
 	Description int
+	// MainContent は本文の重み（見出しは見出しの重みで数えるため、本文からは除いて数えます）
 	MainContent int
+	// 見出しレベルごとの重み（h4〜h6はまとめて H4To6）
+	H1    int
+	H2    int
+	H3    int
+	H4To6 int
+	// ArticleTag は article:tag、NewsKeywords は news_keywords のmetaタグの重み
+	ArticleTag   int
+	NewsKeywords int
+	// StructuredData は構造化データ（JSON-LD・Microdata・RDFa）の headline・keywords・articleSection・about・mentions、
+	// 商品の name・brand・category の重み
+	StructuredData int
+	// 画像の alt・<figcaption>・title 属性・リンクのアンカーテキストの重み（本文と重複することが多いため低め）
+	ImageAlt       int
+	Figcaption     int
+	TitleAttribute int
+	AnchorText     int
 }
 
 // DefaultConfig はデフォルト設定を返します
@@ -90,15 +161,38 @@ func DefaultConfig() Config {
 		Timeout:   10 * time.Second,
 		UserAgent: "Mozilla/5.0 (compatible; KeywordBot/1.0)",
 		ScoreWeights: ScoreWeightConfig{
-			Title:       5,
-			MetaKeyword: 8,
-			Description: 3,
-			MainContent: 1,
+			Title:          5,
+			MetaKeyword:    8,
+			Description:    3,
+			MainContent:    1,
+			H1:             4,
+			H2:             3,
+			H3:             2,
+			H4To6:          1,
+			ArticleTag:     6,
+			NewsKeywords:   6,
+			StructuredData: 6,
+			ImageAlt:       2,
+			Figcaption:     1,
+			TitleAttribute: 1,
+			AnchorText:     1,
 		},
 		MaxKeywords:       20,
 		IgnoreStopWords:   false,
 		EnglishStopWords:  DefaultEnglishStopWords,
 		PluralSingularMap: DefaultPluralSingularMap,
 		InvariantWords:    DefaultInvariantWords,
+		ContentExtractor:  ContentExtractorBoilerplate,
+		IgnoreRobotsTxt:   false,
+		RequestsPerSecond: 2,
+		HostBurst:         2,
+		Retry: RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: 500 * time.Millisecond,
+			MaxBackoff:     10 * time.Second,
+			Jitter:         0.2,
+		},
+		MaxBodyBytes:    10 * 1024 * 1024,
+		FailOnLargeBody: false,
 	}
 }
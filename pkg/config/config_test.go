@@ -16,7 +16,22 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.ScoreWeights.Title != 5 || cfg.ScoreWeights.MetaKeyword != 8 || cfg.ScoreWeights.Description != 3 || cfg.ScoreWeights.MainContent != 1 {
 		t.Errorf("unexpected ScoreWeights: %+v", cfg.ScoreWeights)
 	}
+	if cfg.ScoreWeights.H1 <= cfg.ScoreWeights.H2 || cfg.ScoreWeights.H2 <= cfg.ScoreWeights.H3 || cfg.ScoreWeights.H3 <= cfg.ScoreWeights.H4To6 {
+		t.Errorf("heading weights should decrease by level: %+v", cfg.ScoreWeights)
+	}
+	if cfg.ScoreWeights.ArticleTag <= 0 || cfg.ScoreWeights.NewsKeywords <= 0 || cfg.ScoreWeights.StructuredData <= 0 {
+		t.Errorf("expected article:tag, news_keywords and structured data weights, got %+v", cfg.ScoreWeights)
+	}
+	if w := cfg.ScoreWeights; w.ImageAlt <= 0 || w.Figcaption <= 0 || w.TitleAttribute <= 0 || w.AnchorText <= 0 || w.ImageAlt >= w.Title {
+		t.Errorf("expected low positive weights for image alt, figcaption, title attribute and anchor text, got %+v", w)
+	}
 	if cfg.MaxKeywords != 20 {
 		t.Errorf("expected MaxKeywords 20, got %d", cfg.MaxKeywords)
 	}
+	if cfg.Retry.MaxAttempts < 2 || cfg.Retry.InitialBackoff <= 0 || cfg.Retry.MaxBackoff < cfg.Retry.InitialBackoff {
+		t.Errorf("unexpected Retry policy: %+v", cfg.Retry)
+	}
+	if cfg.MaxBodyBytes <= 0 || cfg.FailOnLargeBody {
+		t.Errorf("expected a body size limit with truncation, got %d (fail: %v)", cfg.MaxBodyBytes, cfg.FailOnLargeBody)
+	}
 }
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+)
+
+// SiteRule はURLパターンに一致するページに適用する抽出ルール
+type SiteRule struct {
+	// URL はルールを適用するURLのパターン（スキームは省略可、"*" は任意の文字列に一致）
+	// "/" を含まない場合はホスト名のみと比較します（例: "example.com", "*.example.com", "example.com/blog/*"）
+	URL string `json:"url"`
+	// Include は指定した重みで追加のキーワードソースとして扱う要素
+	Include []SelectorWeight `json:"include,omitempty"`
+	// Exclude は解析の前に文書から取り除く要素のセレクタ
+	Exclude []string `json:"exclude,omitempty"`
+	// pattern は URL を変換した正規表現（LoadSiteRules でコンパイルし、未設定の場合は Matches で生成します）
+	pattern *regexp.Regexp
+}
+
+// SelectorWeight はCSSセレクタとその重み（正の整数）
+type SelectorWeight struct {
+	Selector string `json:"selector"`
+	Weight   int    `json:"weight"`
+}
+
+// siteRulesFile はルールファイル（JSON）の形式
+type siteRulesFile struct {
+	Rules []SiteRule `json:"rules"`
+}
+
+// LoadSiteRules はJSON形式のルールファイルを読み込み、URLパターン・セレクタ・重みを検証します
+func LoadSiteRules(path string) ([]SiteRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read rules file '%s': %w", path, err)
+	}
+	var file siteRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("Failed to parse rules file '%s': %w", path, err)
+	}
+	for i, rule := range file.Rules {
+		if strings.TrimSpace(rule.URL) == "" {
+			return nil, fmt.Errorf("Missing url in rule #%d of rules file '%s'", i+1, path)
+		}
+		file.Rules[i].pattern = compileSiteRulePattern(rule.URL)
+		selectors := append([]string{}, rule.Exclude...)
+		for _, include := range rule.Include {
+			// 0以下の重みはスコアを減らしてしまうため受け付けない
+			if include.Weight <= 0 {
+				return nil, fmt.Errorf("Invalid weight %d for selector '%s' in rules file '%s': must be positive", include.Weight, include.Selector, path)
+			}
+			selectors = append(selectors, include.Selector)
+		}
+		for _, selector := range selectors {
+			if _, err := cascadia.Compile(selector); err != nil {
+				return nil, fmt.Errorf("Invalid selector '%s' in rules file '%s': %w", selector, path, err)
+			}
+		}
+	}
+	return file.Rules, nil
+}
+
+// Matches はURLがルールのパターンに一致するか判定します（大文字小文字は区別しない）
+func (r SiteRule) Matches(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	re := r.pattern
+	if re == nil {
+		re = compileSiteRulePattern(r.URL)
+	}
+	target := u.Hostname()
+	if strings.Contains(trimScheme(r.URL), "/") {
+		path := u.EscapedPath()
+		if path == "" {
+			path = "/"
+		}
+		target += path
+	}
+	return re.MatchString(target)
+}
+
+// compileSiteRulePattern はルールのURLパターンを大文字小文字を区別しない正規表現に変換します
+func compileSiteRulePattern(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(trimScheme(pattern)), `\*`, ".*") + "$")
+}
+
+// trimScheme はURLパターンから http:// または https:// を取り除きます
+func trimScheme(pattern string) string {
+	for _, scheme := range []string{"http://", "https://"} {
+		pattern = strings.TrimPrefix(pattern, scheme)
+	}
+	return pattern
+}
+
+// MatchSiteRules はURLに一致するルールを定義順に返します
+func MatchSiteRules(rules []SiteRule, rawURL string) []SiteRule {
+	var matched []SiteRule
+	for _, rule := range rules {
+		if rule.Matches(rawURL) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
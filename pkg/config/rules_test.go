@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRulesFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	return path
+}
+
+func TestLoadSiteRules(t *testing.T) {
+	path := writeRulesFile(t, `{"rules":[
+		{"url":"example.com","include":[{"selector":".article-body","weight":2}],"exclude":[".recommend"]},
+		{"url":"https://*.example.org/blog/*","exclude":["aside.related"]}]}`)
+	rules, err := LoadSiteRules(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Include[0].Selector != ".article-body" || rules[0].Include[0].Weight != 2 || rules[0].Exclude[0] != ".recommend" {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+	// URLパターンは読み込み時に一度だけコンパイルする
+	if rules[0].pattern == nil || rules[1].pattern == nil {
+		t.Error("expected URL patterns to be compiled when loading")
+	}
+
+	for _, tc := range []struct {
+		url     string
+		matched int
+	}{
+		{"https://example.com/news/1", 1},
+		{"https://EXAMPLE.com/", 1},
+		{"https://www.example.com/", 0},
+		{"https://news.example.org/blog/post", 1},
+		{"https://news.example.org/about", 0},
+		{"https://example.org/blog/post", 0},
+	} {
+		if matched := MatchSiteRules(rules, tc.url); len(matched) != tc.matched {
+			t.Errorf("%s: expected %d matching rules, got %+v", tc.url, tc.matched, matched)
+		}
+	}
+}
+
+func TestLoadSiteRules_Invalid(t *testing.T) {
+	for _, content := range []string{
+		`{"rules":[{"url":"example.com","exclude":["div["]}]}`,
+		`{"rules":[{"include":[{"selector":"p","weight":1}]}]}`,
+		`{"rules":[{"url":"example.com","include":[{"selector":"p","weight":0}]}]}`,
+		`{"rules":[{"url":"example.com","include":[{"selector":"p","weight":-2}]}]}`,
+		`{"rules":`,
+	} {
+		if _, err := LoadSiteRules(writeRulesFile(t, content)); err == nil {
+			t.Errorf("expected error for rules file %s", content)
+		}
+	}
+	if _, err := LoadSiteRules(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "Failed to read rules file") {
+		t.Errorf("expected read error, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -21,9 +22,91 @@ type KeywordWithScore struct {
 
 // AnalysisResult はウェブページの解析結果を表す構造体
 type AnalysisResult struct {
+	URL      string             `json:"url,omitempty"`
 	Title    string             `json:"title,omitempty"`
 	MetaTags map[string]string  `json:"meta_tags,omitempty"`
 	Keywords []KeywordWithScore `json:"keywords,omitempty"`
+	Charset  string             `json:"charset,omitempty"`
+	// FetchAttempts は再試行を含むHTTPリクエストの試行回数（HTTP取得していない場合は0）
+	FetchAttempts int `json:"fetch_attempts,omitempty"`
+	// Truncated はレスポンスボディが上限サイズで切り詰められ、先頭部分のみを解析した場合にtrue
+	Truncated bool `json:"truncated,omitempty"`
+	// Fetch はHTTP取得時のレスポンス情報（HTTP取得していない場合はnil）
+	Fetch *FetchInfo `json:"fetch,omitempty"`
+	// Canonical・AMPURL は <link rel="canonical">・<link rel="amphtml"> のURL
+	Canonical string `json:"canonical,omitempty"`
+	AMPURL    string `json:"amp_url,omitempty"`
+	// Alternates は hreflang を指定した <link rel="alternate"> の言語・地域別ページ
+	Alternates []Alternate `json:"alternates,omitempty"`
+	// BaseURL は <base href> で指定された相対URLの基準（指定がない場合は空）
+	BaseURL string `json:"base_url,omitempty"`
+	// StructuredData はページに埋め込まれた構造化データ（schema.org）
+	StructuredData []StructuredData `json:"structured_data,omitempty"`
+	// Error はバッチ解析などでURLごとの失敗を結果に含める場合に設定されます
+	Error string `json:"error,omitempty"`
+}
+
+// FetchInfo はHTTP取得時のレスポンス情報（SEOのデバッグ用）
+type FetchInfo struct {
+	StatusCode int    `json:"status_code"`
+	FinalURL   string `json:"final_url"`
+	// Redirects は最終URLに至るまでに経由したリダイレクト（古い順）
+	Redirects    []Redirect `json:"redirects,omitempty"`
+	ContentType  string     `json:"content_type,omitempty"`
+	LastModified string     `json:"last_modified,omitempty"`
+	ETag         string     `json:"etag,omitempty"`
+	XRobotsTag   string     `json:"x_robots_tag,omitempty"`
+	// DurationMs は再試行を含む取得にかかった時間（ミリ秒）
+	DurationMs int64 `json:"duration_ms"`
+	// Bytes は受信したボディのバイト数（圧縮されている場合は展開前）
+	Bytes int64 `json:"bytes"`
+	// Cache はディスクキャッシュの利用状況（"hit", "revalidated", "miss"）
+	Cache string `json:"cache,omitempty"`
+}
+
+// Redirect はリダイレクト元のURLとそのステータスコード
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// Alternate は hreflang で示された言語・地域別の代替ページ
+type Alternate struct {
+	Hreflang string `json:"hreflang"`
+	URL      string `json:"url"`
+}
+
+// StructuredData は構造化データ（schema.org）の1つのオブジェクトのうち、キーワードに関係する項目
+type StructuredData struct {
+	// Format は構造化データの形式（"json-ld", "microdata", "rdfa"）
+	Format string `json:"format"`
+	// Type は schema.org の型（"Article" など。複数指定の場合はカンマ区切り）
+	Type           string   `json:"type,omitempty"`
+	Name           string   `json:"name,omitempty"`
+	Headline       string   `json:"headline,omitempty"`
+	Keywords       []string `json:"keywords,omitempty"`
+	ArticleSection []string `json:"article_section,omitempty"`
+	// About・Mentions は主題・言及されている対象の名前
+	About    []string `json:"about,omitempty"`
+	Mentions []string `json:"mentions,omitempty"`
+	// Brand・Category は商品（Product）のブランド名とカテゴリ
+	Brand    string   `json:"brand,omitempty"`
+	Category []string `json:"category,omitempty"`
+}
+
+// SiteKeyword はサイト全体で集計したキーワード
+// Score は各ページのスコアの合計、Pages はそのキーワードが出現したページ数
+type SiteKeyword struct {
+	Keyword string `json:"keyword"`
+	Score   int    `json:"score"`
+	Pages   int    `json:"pages"`
+}
+
+// SiteAnalysisResult はサイトをクロールした解析結果（ページごとの結果とサイト全体の集計）
+type SiteAnalysisResult struct {
+	URL      string           `json:"url,omitempty"`
+	Keywords []SiteKeyword    `json:"keywords,omitempty"`
+	Pages    []AnalysisResult `json:"pages,omitempty"`
 }
 
 // PageFetcher: ページ取得のインターフェース
@@ -31,6 +114,27 @@ type PageFetcher interface {
 	Fetch(url string, timeout time.Duration) ([]byte, error)
 }
 
+// ContextPageFetcher: contextによるキャンセルに対応し、Content-Typeや取得情報を含む結果を返すページ取得のインターフェース
+type ContextPageFetcher interface {
+	PageFetcher
+	FetchPage(ctx context.Context, url string) (*FetchedPage, error)
+}
+
+// FetchedPage は ContextPageFetcher が返すページの取得結果
+type FetchedPage struct {
+	// URL はリダイレクト後の最終URL
+	URL string
+	// ContentType は文字コードの判定に使用するContent-Typeヘッダ（空の場合はBOM・metaタグから判定）
+	ContentType string
+	Body        []byte
+	// Attempts は再試行を含むリクエストの試行回数
+	Attempts int
+	// Truncated はボディがサイズの上限で切り詰められた場合にtrue
+	Truncated bool
+	// Info はリダイレクトやレスポンスヘッダなどの取得情報（nilの場合は出力しない）
+	Info *FetchInfo
+}
+
 // KeywordExtractor: キーワード抽出のインターフェース
 type KeywordExtractor interface {
 	Extract(text string) ([]KeywordWithScore, error)
@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	cases := map[string]string{
+		"HTTP://Example.COM":               "http://example.com/",
+		"https://example.com:443/a#frag":   "https://example.com/a",
+		"http://example.com:8080/?b=2&a=1": "http://example.com:8080/?a=1&b=2",
+	}
+	for in, expected := range cases {
+		out, err := NormalizeURL(in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out != expected {
+			t.Errorf("NormalizeURL(%s): expected %s, got %s", in, expected, out)
+		}
+	}
+	if _, err := NormalizeURL("mailto:a@example.com"); err == nil {
+		t.Error("expected error for non-http URL")
+	}
+}